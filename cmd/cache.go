@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"huseynovvusal/gitai/internal/cache"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the cache of generated commit messages",
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached response",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, ok := openCache(cmd)
+		if !ok {
+			os.Exit(1)
+		}
+
+		n, err := c.Clear()
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		cmd.Printf("Removed %d cached response(s)\n", n)
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show where the cache lives and how much it holds",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c, ok := openCache(cmd)
+		if !ok {
+			os.Exit(1)
+		}
+
+		s, err := c.Stats()
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+
+		tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Directory:\t%s\n", s.Dir)
+		fmt.Fprintf(tw, "Entries:\t%d (%d expired)\n", s.Entries, s.Expired)
+		fmt.Fprintf(tw, "Size:\t%s of %s\n", formatBytes(s.Bytes), formatBytes(s.Limit))
+		if s.Entries > 0 {
+			fmt.Fprintf(tw, "Oldest:\t%s\n", s.Oldest.Format(time.DateTime))
+			fmt.Fprintf(tw, "Newest:\t%s\n", s.Newest.Format(time.DateTime))
+		}
+		_ = tw.Flush()
+	},
+}
+
+func openCache(cmd *cobra.Command) (*cache.Cache, bool) {
+	cfg, ok := loadConfig(cmd)
+	if !ok {
+		return nil, false
+	}
+	c, err := cache.Open(cfg.Cache)
+	if err != nil {
+		cmd.PrintErrln(err)
+		return nil, false
+	}
+	return c, true
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd, cacheStatsCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/config"
+	"huseynovvusal/gitai/internal/credentials"
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/security"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and edit the gitai configuration",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactively create the user config file (~/.config/gitai/gitai.yaml)",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+
+		path, err := config.UserConfigPath()
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		if _, err := os.Stat(path); err == nil {
+			if !force {
+				cmd.PrintErrf("%s already exists; use --force to replace it or `gitai config set` to edit it\n", path)
+				os.Exit(1)
+			}
+			if err := os.Rename(path, path+".bak"); err != nil {
+				cmd.PrintErrln(err)
+				os.Exit(1)
+			}
+			cmd.Printf("Moved the existing file to %s.bak\n", path)
+		}
+
+		values, err := runConfigWizard(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		if err := config.SetInFile(path, values...); err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		cmd.Printf("Wrote %s\n", path)
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration and where each value comes from",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		v := viper.GetViper()
+		out := cmd.OutOrStdout()
+
+		file := v.ConfigFileUsed()
+		if file == "" {
+			file = "(none found)"
+		}
+		fmt.Fprintf(out, "Config file: %s\n", file)
+		fmt.Fprintf(out, "Searched:    %s\n", strings.Join(configPaths(), ", "))
+
+		profile, err := config.ApplyProfile(v, config.CurrentRepo())
+		if err != nil {
+			fmt.Fprintf(out, "Profile:     error: %v\n", err)
+		} else if profile != "" {
+			fmt.Fprintf(out, "Profile:     %s\n", profile)
+		}
+		fmt.Fprintln(out)
+
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+		for _, s := range config.Describe(v) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+		}
+		_ = tw.Flush()
+
+		if unknown := config.UnknownKeys(v); len(unknown) > 0 {
+			fmt.Fprintln(out)
+			for _, k := range unknown {
+				fmt.Fprintf(out, "warning: unknown key %q is ignored\n", k)
+			}
+		}
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a config key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		key := strings.ToLower(args[0])
+		reveal, _ := cmd.Flags().GetBool("reveal")
+
+		if !config.IsKnownKey(key) {
+			cmd.PrintErrf("unknown config key %q\n", key)
+			os.Exit(1)
+		}
+		if _, err := config.ApplyProfile(viper.GetViper(), config.CurrentRepo()); err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+
+		value := config.FormatValue(viper.Get(key))
+		if config.IsSecretKey(key) && !reveal {
+			value = config.MaskSecret(value)
+		}
+		cmd.Println(value)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a key in the config file (lists as YAML, e.g. \"[vendor/, '*.lock']\")",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := configSetPath(cmd)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+
+		if err := config.SetInFile(path, config.KeyValue{Key: args[0], Value: args[1]}); err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		cmd.Printf("Set %s in %s\n", strings.ToLower(args[0]), path)
+	},
+}
+
+// configSetPath returns the file `config set` edits: the user config file
+// unless --repo or --file names another one. Like git config, it never
+// guesses from the file in effect, which may belong to the repository or
+// the system.
+func configSetPath(cmd *cobra.Command) (string, error) {
+	if path, _ := cmd.Flags().GetString("file"); path != "" {
+		return path, nil
+	}
+	if repo, _ := cmd.Flags().GetBool("repo"); repo {
+		root, err := git.GetGitRoot()
+		if err != nil {
+			return "", fmt.Errorf("--repo needs a git repository: %w", err)
+		}
+		return config.RepoConfigPath(root), nil
+	}
+	return config.UserConfigPath()
+}
+
+var configSetKeyCmd = &cobra.Command{
+	Use:   "set-key <provider>",
+	Short: "Save a provider's API key in the OS keyring (or the credentials file)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		provider, err := ai.ParseProvider(args[0])
+		if err != nil || (provider != ai.ProviderGPT && provider != ai.ProviderGemini) {
+			cmd.PrintErrf("%s does not use an API key (expected gpt|gemini)\n", args[0])
+			os.Exit(1)
+		}
+
+		storeFlag, _ := cmd.Flags().GetString("store")
+		if storeFlag == "" {
+			storeFlag = viper.GetString("ai.credential_store")
+		}
+		store, err := credentials.ParseStore(storeFlag)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		if store == credentials.StoreNone {
+			store = credentials.StoreKeyring
+		}
+
+		key, err := askSecret(bufio.NewReader(cmd.InOrStdin()), cmd.OutOrStdout(), fmt.Sprintf("%s API key", provider))
+		if err != nil || key == "" {
+			cmd.PrintErrln("no key entered")
+			os.Exit(1)
+		}
+
+		where, err := credentials.Save(store, string(provider), key)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		cmd.Printf("Saved the %s key in %s\n", provider, where)
+		if viper.GetString("ai.credential_store") == "" {
+			cmd.Printf("Run `gitai config set ai.credential_store %s` so gitai reads it\n", store)
+		}
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for invalid values and unknown keys",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		v := viper.GetViper()
+		failed := false
+
+		for _, k := range config.UnknownKeys(v) {
+			cmd.PrintErrf("unknown key %q\n", k)
+			failed = true
+		}
+
+		if _, ok := loadConfig(cmd); !ok {
+			failed = true
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+
+		file := v.ConfigFileUsed()
+		if file == "" {
+			file = "defaults and environment"
+		}
+		cmd.Printf("Configuration is valid (%s)\n", file)
+	},
+}
+
+// runConfigWizard asks for the essential settings and returns them as config
+// assignments.
+func runConfigWizard(cmd *cobra.Command) ([]config.KeyValue, error) {
+	in := bufio.NewReader(cmd.InOrStdin())
+	out := cmd.OutOrStdout()
+
+	providerStr, err := ask(in, out, "AI provider (gpt|gemini|ollama|geminicli)", "gpt")
+	if err != nil {
+		return nil, err
+	}
+	provider, err := ai.ParseProvider(providerStr)
+	if err != nil || provider == ai.ProviderNone {
+		return nil, fmt.Errorf("unknown provider: %s", providerStr)
+	}
+	values := []config.KeyValue{{Key: "ai.provider", Value: string(provider)}}
+
+	switch provider {
+	case ai.ProviderGPT, ai.ProviderGemini:
+		key, err := askSecret(in, out, "API key (leave empty to use the environment)")
+		if err != nil {
+			return nil, err
+		}
+		section, def := "openai", ai.DefaultOpenAIModel
+		if provider == ai.ProviderGemini {
+			section, def = "gemini", ai.DefaultGeminiModel
+		}
+		if key != "" {
+			values = append(values, config.KeyValue{Key: section + ".api_key", Value: key})
+		}
+		model, err := ask(in, out, "Model", def)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, config.KeyValue{Key: section + ".model", Value: model})
+	case ai.ProviderOllama:
+		def, _ := exec.LookPath("ollama")
+		path, err := ask(in, out, "Path to the ollama binary", def)
+		if err != nil {
+			return nil, err
+		}
+		model, err := ask(in, out, "Model", ai.DefaultOllamaModel)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values,
+			config.KeyValue{Key: "ollama.path", Value: path},
+			config.KeyValue{Key: "ollama.model", Value: model})
+	}
+
+	policy, err := ask(in, out, "When secrets are detected (warn|redact|block)", string(security.DefaultPolicy))
+	if err != nil {
+		return nil, err
+	}
+	return append(values, config.KeyValue{Key: "security.policy", Value: policy}), nil
+}
+
+func ask(in *bufio.Reader, out io.Writer, question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", question)
+	}
+
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// askSecret reads without echo when attached to a terminal.
+func askSecret(in *bufio.Reader, out io.Writer, question string) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return ask(in, out, question, "")
+	}
+
+	fmt.Fprintf(out, "%s: ", question)
+	b, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(out)
+	return strings.TrimSpace(string(b)), err
+}
+
+func init() {
+	configInitCmd.Flags().Bool("force", false, "Replace an existing user config file (the old one is kept as .bak)")
+	configGetCmd.Flags().Bool("reveal", false, "Print secrets such as API keys unmasked")
+	configSetKeyCmd.Flags().String("store", "", "Where to save the key: keyring (falls back to file) or file (default: ai.credential_store, else keyring)")
+	configSetCmd.Flags().String("file", "", "Config file to edit (default: ~/.config/gitai/gitai.yaml)")
+	configSetCmd.Flags().Bool("repo", false, "Edit gitai.yaml at the root of the current repository")
+	configSetCmd.MarkFlagsMutuallyExclusive("file", "repo")
+
+	configCmd.AddCommand(configInitCmd, configShowCmd, configGetCmd, configSetCmd, configSetKeyCmd, configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
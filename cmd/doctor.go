@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"huseynovvusal/gitai/internal/config"
+	"huseynovvusal/gitai/internal/doctor"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check git, configuration, provider access, hooks and the terminal",
+	Long: `Doctor runs a series of environment checks and prints pass/warn/fail for each.
+It exits with status 1 if any check fails.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		format, _ := cmd.Flags().GetString("format")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		v := viper.GetViper()
+		opts := doctor.Options{
+			ConfigFile:  v.ConfigFileUsed(),
+			UnknownKeys: config.UnknownKeys(v),
+			Timeout:     timeout,
+		}
+
+		// a broken config is a finding, not a reason to stop
+		if _, err := config.ApplyProfile(v, config.CurrentRepo()); err != nil {
+			opts.ConfigErr = err
+		} else if cfg, err := config.LoadConfig(v); err != nil {
+			opts.ConfigErr = err
+		} else {
+			if key, _ := cmd.Flags().GetString("api_key"); key != "" {
+				cfg.SetAPIKey(key)
+			}
+			// a failing api_key_command leaves the key empty, which the api key check reports
+			if err := cfg.ResolveAPIKey(ctx); err != nil {
+				cmd.PrintErrln(err)
+			}
+			opts.Config = cfg
+		}
+
+		results := doctor.Run(ctx, opts)
+		if err := doctor.WriteReport(cmd.OutOrStdout(), format, results); err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(2)
+		}
+		if doctor.Failed(results) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringP("format", "f", doctor.FormatText, "Output format: text or json")
+	doctorCmd.Flags().Duration("timeout", doctor.DefaultTimeout, "Timeout for each network probe and external command")
+	doctorCmd.Flags().StringP("api_key", "k", "", "API key to check instead of the configured one")
+	rootCmd.AddCommand(doctorCmd)
+}
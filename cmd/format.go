@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var formatCmd = &cobra.Command{
+	Use:   "format",
+	Short: "Inspect the commit message format presets",
+}
+
+var formatListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the format presets with their header pattern and examples",
+	Long: `List shows the built-in presets (conventional, angular, gitmoji, kernel, plain)
+and the ones defined in format.presets; the preset selected by format.preset is
+marked with *.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, ok := loadConfig(cmd)
+		if !ok {
+			os.Exit(1)
+		}
+
+		out := cmd.OutOrStdout()
+		for i, p := range cfg.Format.All() {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			mark := " "
+			if strings.EqualFold(p.Name, cfg.Preset().Name) {
+				mark = "*"
+			}
+			fmt.Fprintf(out, "%s %s\n", mark, p.Name)
+			if p.Pattern != "" {
+				fmt.Fprintf(out, "    header: %s\n", p.Pattern)
+			}
+			for _, e := range p.Examples {
+				for j, line := range strings.Split(e, "\n") {
+					switch {
+					case j == 0:
+						fmt.Fprintf(out, "    e.g. %s\n", line)
+					case line == "":
+						fmt.Fprintln(out)
+					default:
+						fmt.Fprintf(out, "         %s\n", line)
+					}
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	formatCmd.AddCommand(formatListCmd)
+	rootCmd.AddCommand(formatCmd)
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"huseynovvusal/gitai/internal/lint"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var lintMsgCmd = &cobra.Command{
+	Use:   "lint-msg <file>",
+	Short: "Check a commit message file against the lint rules (commit-msg hook)",
+	Long: `Lint-msg checks a commit message against the lint.* rules: Conventional Commits
+header, allowed types and scopes, subject length, a blank line before the body,
+body wrapping, and no markdown fences or chatty preambles. Comment lines and
+everything below git's scissors line are ignored; merge, revert and fixup
+messages are not checked. Use "-" to read from stdin.
+
+It exits with status 1 on violations, so it can run as a commit-msg hook:
+
+    # .git/hooks/commit-msg
+    #!/bin/sh
+    exec gitai lint-msg "$1"
+
+With --fix, what can be fixed mechanically is rewritten in the file first.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fix, _ := cmd.Flags().GetBool("fix")
+		path := args[0]
+
+		cfg, ok := loadConfig(cmd)
+		if !ok {
+			os.Exit(2)
+		}
+
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(2)
+		}
+
+		msg := lint.Clean(string(data))
+		var violations []lint.Violation
+		if fix {
+			var fixed string
+			fixed, violations = lint.Repair(cfg.LintConfig(), msg)
+			if path == "-" {
+				fmt.Fprintln(cmd.OutOrStdout(), fixed)
+			} else if fixed != msg {
+				if err := os.WriteFile(path, []byte(fixed+"\n"), 0o644); err != nil {
+					cmd.PrintErrln(err)
+					os.Exit(2)
+				}
+			}
+		} else {
+			violations = lint.Lint(cfg.LintConfig(), msg)
+		}
+
+		if len(violations) == 0 {
+			return
+		}
+		name := path
+		if name == "-" {
+			name = "<stdin>"
+		}
+		for _, v := range violations {
+			cmd.PrintErrf("%s: %s\n", name, v)
+		}
+		os.Exit(1)
+	},
+}
+
+func init() {
+	lintMsgCmd.Flags().Bool("fix", false, "Rewrite the file with mechanical fixes (fences, preambles, header punctuation, blank line, wrapping) before checking")
+	rootCmd.AddCommand(lintMsgCmd)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"huseynovvusal/gitai/internal/config"
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/ignore"
+	"huseynovvusal/gitai/internal/prompt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the prompt sent to the provider",
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:   "show [file...]",
+	Short: "Render the commit prompt for the changed files without calling a provider",
+	Long: `Show renders the system and user messages that gitai suggest would send for the
+given files (all changed files by default), using the configured or repository
+templates. Content of prompt.exclude_paths is withheld and, under
+security.policy=redact, secrets are replaced by placeholders as in gitai suggest,
+so the output is what the provider receives. --no-redact shows the raw diff.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, ok := loadConfig(cmd)
+		if !ok {
+			os.Exit(1)
+		}
+		applyStyleFlag(cmd, cfg)
+		if !applyLangFlag(cmd, cfg) {
+			os.Exit(1)
+		}
+
+		files := args
+		if len(files) == 0 {
+			changed, err := git.GetChangedFiles()
+			if err != nil {
+				cmd.PrintErrln(err)
+				os.Exit(1)
+			}
+			files = changed
+		}
+		if len(files) == 0 {
+			cmd.PrintErrln("No changed files; pass files to render the prompt for.")
+			os.Exit(1)
+		}
+
+		exclude, err := ignore.Load(cfg.Prompt.ExcludePaths)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		templates, err := prompt.Load(cfg)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+
+		included, withheld := exclude.Split(files)
+		diff, err := git.GetChangesForFiles(included)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		status, err := git.GetStatusForFiles(files)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		diff += ignore.WithheldSummary(withheld)
+
+		if noRedact, _ := cmd.Flags().GetBool("no-redact"); !noRedact {
+			if diff, _, err = prompt.Redact(cfg, diff); err != nil {
+				cmd.PrintErrln(err)
+				os.Exit(1)
+			}
+		}
+
+		p, err := templates.Render(prompt.Gather(cfg, files, diff, status))
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		p = p.ForOutput(cfg.AIConfig().Output)
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "=== system (%s) ===\n%s\n\n", templates.SystemSource, strings.TrimRight(p.System, "\n"))
+		fmt.Fprintf(out, "=== user (%s) ===\n%s\n", templates.CommitSource, strings.TrimRight(p.User, "\n"))
+	},
+}
+
+// addStyleFlag adds --style-examples, which overrides prompt.style_examples.
+func addStyleFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("style-examples", 0, "Sample the last N commit messages as examples of the repository's style (overrides prompt.style_examples)")
+}
+
+func applyStyleFlag(cmd *cobra.Command, cfg *config.Config) {
+	if cmd.Flags().Changed("style-examples") {
+		cfg.Prompt.StyleExamples, _ = cmd.Flags().GetInt("style-examples")
+	}
+}
+
+// addLangFlag adds --lang, which overrides ai.language.
+func addLangFlag(cmd *cobra.Command) {
+	cmd.Flags().String("lang", "", "Write the commit message in this language, e.g. de or Japanese (overrides ai.language)")
+}
+
+func applyLangFlag(cmd *cobra.Command, cfg *config.Config) bool {
+	if !cmd.Flags().Changed("lang") {
+		return true
+	}
+	lang, _ := cmd.Flags().GetString("lang")
+	if err := cfg.SetLanguage(lang); err != nil {
+		cmd.PrintErrln(err)
+		return false
+	}
+	return true
+}
+
+func init() {
+	addStyleFlag(promptShowCmd)
+	addLangFlag(promptShowCmd)
+	promptShowCmd.Flags().Bool("no-redact", false, "Show the diff without redacting secrets (security.policy=redact); the output may contain live secrets")
+	promptCmd.AddCommand(promptShowCmd)
+	rootCmd.AddCommand(promptCmd)
+}
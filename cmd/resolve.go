@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"huseynovvusal/gitai/internal/tui/resolve"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "Resolve merge conflicts hunk by hunk with AI proposals",
+	// suggest binds the same key in init; rebind here so this command's flag wins
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("ai.provider", cmd.Flags().Lookup("provider"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		rootCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		cfg, ok := loadAIConfig(rootCtx, cmd)
+		if !ok {
+			return
+		}
+
+		resolve.RunResolveFlow(rootCtx, cfg)
+	},
+}
+
+func init() {
+	resolveCmd.Flags().StringP("provider", "p", "", "AI provider to use (gpt|gemini|ollama|geminicli). If empty, uses env or config or default")
+	resolveCmd.Flags().StringP("api_key", "k", "", "Optional API key for the selected provider; overrides config, environment and stored keys")
+	rootCmd.AddCommand(resolveCmd)
+}
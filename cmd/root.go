@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"huseynovvusal/gitai/internal/config"
 	"huseynovvusal/gitai/internal/git"
 	"os"
 	"path/filepath"
@@ -19,54 +21,97 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().String("profile", "", "Named config profile to use (default: matched by repository remote or path)")
+	_ = viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 }
 
 func initConfig() {
 	// --- Config File Definition ---
-	viper.SetConfigName("gitai") // Searches for a file named 'gitai'
+	viper.SetConfigName(config.FileName) // Searches for a file named 'gitai'
+	for _, p := range configPaths() {
+		viper.AddConfigPath(p)
+	}
+
+	// --- Environment Variable Setup (High Precedence) ---
+
+	// Sets the prefix for environment variables, e.g., GITAI_API_KEY
+	viper.SetEnvPrefix("gitai")
+
+	// Replaces dots in config keys with underscores for ENV var mapping
+	// e.g., config key "ai.api_key" maps to ENV var GITAI_AI_API_KEY
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	// 1. System-wide configuration (highest precedence for fixed environment setup)
-	viper.AddConfigPath("/etc/gitai/")
+	// Enable automatic reading of environment variables
+	viper.AutomaticEnv()
+	// Registering every key lets Unmarshal see env vars without a config file;
+	// this also binds aliases such as OLLAMA_API_PATH
+	config.SetDefaults(viper.GetViper())
+
+	// --- Read Configuration ---
+
+	// Read the config file if present;
+	// Viper reads the first file found in the search paths (see `gitai config show`).
+	_ = viper.ReadInConfig()
+}
+
+// configPaths lists the directories searched for the config file. Viper
+// reads the first file found, so earlier paths win.
+func configPaths() []string {
+	// 1. System-wide configuration (for fixed environment setup)
+	paths := []string{"/etc/gitai/"}
 
 	// 2. User Home Directory paths (for user-specific settings)
 	if home, err := os.UserHomeDir(); err == nil {
 		// XDG Base Directory Specification (recommended user config path on modern Linux/Mac)
 		// e.g., /home/user/.config/gitai/
-		viper.AddConfigPath(filepath.Join(home, ".config", "gitai"))
+		paths = append(paths, filepath.Join(home, ".config", "gitai"))
 
 		// Traditional dot-directory in home (common fallback)
 		// e.g., /home/user/.gitai/
-		viper.AddConfigPath(filepath.Join(home, ".gitai"))
+		paths = append(paths, filepath.Join(home, ".gitai"))
 	}
+
 	// 3. Current Git repository root directory
 	if gitRoot, err := git.GetGitRoot(); err == nil {
-		viper.AddConfigPath(gitRoot)
+		paths = append(paths, gitRoot)
 	}
 
 	// 4. Current Working Directory (for local development/overrides)
-	viper.AddConfigPath(".")
-
-	// --- Environment Variable Setup (High Precedence) ---
-
-	// Sets the prefix for environment variables, e.g., GITAI_API_KEY
-	viper.SetEnvPrefix("gitai")
+	return append(paths, ".")
+}
 
-	// Replaces dots in config keys with underscores for ENV var mapping
-	// e.g., config key "ai.api_key" maps to ENV var GITAI_AI_API_KEY
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+// loadConfig applies the selected profile and returns the typed
+// configuration. Commands call it from Run so that per-command flag bindings apply.
+func loadConfig(cmd *cobra.Command) (*config.Config, bool) {
+	if _, err := config.ApplyProfile(viper.GetViper(), config.CurrentRepo()); err != nil {
+		cmd.PrintErrln(err)
+		return nil, false
+	}
 
-	// Enable automatic reading of environment variables
-	viper.AutomaticEnv()
-	_ = viper.BindEnv("ollama.path", "OLLAMA_API_PATH")
-	_ = viper.BindEnv("ai.api_key", "OPENAI_API_KEY")
-	_ = viper.BindEnv("ai.api_key", "GEMINI_API_KEY")
-	_ = viper.BindEnv("ai.api_key", "GITAI_API_KEY")
+	cfg, err := config.LoadConfig(viper.GetViper())
+	if err != nil {
+		cmd.PrintErrln(err)
+		return nil, false
+	}
+	return cfg, true
+}
 
-	// --- Read Configuration ---
+// loadAIConfig is loadConfig for commands that call a provider: it applies
+// the command's --api_key flag and resolves the provider's key.
+func loadAIConfig(ctx context.Context, cmd *cobra.Command) (*config.Config, bool) {
+	cfg, ok := loadConfig(cmd)
+	if !ok {
+		return nil, false
+	}
 
-	// Read the config file if present;
-	// Viper loads configuration from all found paths, merging them.
-	_ = viper.ReadInConfig()
+	if key, _ := cmd.Flags().GetString("api_key"); key != "" {
+		cfg.SetAPIKey(key)
+	}
+	if err := cfg.ResolveAPIKey(ctx); err != nil {
+		cmd.PrintErrln(err)
+		return nil, false
+	}
+	return cfg, true
 }
 
 func Execute() {
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/security"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan changes for secrets and exit non-zero on findings",
+	Long: `Scan staged changes (default), the whole working tree, or a commit range for secrets
+using the same rules as the suggest flow. Exits with status 1 when findings at or above
+--fail-on are reported and 2 on errors, so it can be used as a pre-commit hook or CI gate.
+
+With --history every commit's patch is scanned and each secret is reported once with the
+commit and author that introduced it. Without --range the scan is incremental: it starts
+after HEAD of the last --history run that found nothing (use --full to start over).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		worktree, _ := cmd.Flags().GetBool("worktree")
+		revRange, _ := cmd.Flags().GetString("range")
+		format, _ := cmd.Flags().GetString("format")
+		failOn, _ := cmd.Flags().GetString("fail-on")
+		history, _ := cmd.Flags().GetBool("history")
+		full, _ := cmd.Flags().GetBool("full")
+		pii, _ := cmd.Flags().GetBool("pii")
+
+		if staged, _ := cmd.Flags().GetBool("staged"); !staged && cmd.Flags().Changed("staged") {
+			cmd.PrintErrln("--staged=false leaves nothing to scan; use --worktree, --range or --history")
+			os.Exit(2)
+		}
+
+		threshold := security.Severity(failOn)
+		if threshold.Rank() == 0 {
+			cmd.PrintErrln("Invalid --fail-on severity:", failOn)
+			os.Exit(2)
+		}
+
+		cfg, ok := loadConfig(cmd)
+		if !ok {
+			os.Exit(2)
+		}
+		newScanner := security.NewScanner
+		if pii {
+			newScanner = security.NewPIIScanner
+		}
+		scanner, err := newScanner(cfg.Security)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(2)
+		}
+
+		var findings []security.Finding
+		if history {
+			findings, err = scanHistory(scanner, revRange, full)
+		} else {
+			findings, err = scanDiff(scanner, revRange, worktree)
+		}
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(2)
+		}
+
+		if err := security.WriteReport(cmd.OutOrStdout(), format, findings, scanner.Rules()); err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(2)
+		}
+
+		for _, f := range findings {
+			if f.Severity.Rank() >= threshold.Rank() {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func scanDiff(scanner *security.Scanner, revRange string, worktree bool) ([]security.Finding, error) {
+	var diffText string
+	var err error
+	switch {
+	case revRange != "":
+		diffText, err = git.GetRangeDiff(revRange)
+	case worktree:
+		diffText, err = git.GetWorktreeDiff()
+	default:
+		diffText, err = git.GetStagedDiff()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	findings, err := scanner.ScanDiff(diffText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse diff: %w", err)
+	}
+	return findings, nil
+}
+
+// scanHistory scans revRange, or incrementally from the last clean scan to
+// HEAD when no range is given.
+func scanHistory(scanner *security.Scanner, revRange string, full bool) ([]security.Finding, error) {
+	if revRange != "" {
+		return scanner.ScanHistory(revRange)
+	}
+	return scanner.ScanNewHistory(full)
+}
+
+func init() {
+	scanCmd.Flags().Bool("staged", false, "Scan staged changes (the default without --worktree, --range or --history)")
+	scanCmd.Flags().Bool("worktree", false, "Scan all uncommitted changes, staged and unstaged")
+	scanCmd.Flags().String("range", "", "Scan a commit range, e.g. origin/main..HEAD")
+	scanCmd.Flags().StringP("format", "f", security.FormatText, fmt.Sprintf("Output format (%s|%s|%s)", security.FormatText, security.FormatJSON, security.FormatSARIF))
+	scanCmd.Flags().String("fail-on", string(security.SeverityLow), "Minimum severity that makes the command fail (low|medium|high|critical)")
+	scanCmd.Flags().Bool("history", false, "Scan every commit in --range (or the history since the last scan) instead of a diff")
+	scanCmd.Flags().Bool("pii", false, "Also apply the PII rules (emails, phone numbers, IBANs, card numbers, national IDs)")
+	scanCmd.Flags().Bool("full", false, "With --history, ignore the last scanned commit and scan the whole history")
+	scanCmd.MarkFlagsMutuallyExclusive("staged", "worktree", "range")
+	scanCmd.MarkFlagsMutuallyExclusive("staged", "history")
+	scanCmd.MarkFlagsMutuallyExclusive("worktree", "range")
+	scanCmd.MarkFlagsMutuallyExclusive("worktree", "history")
+	rootCmd.AddCommand(scanCmd)
+}
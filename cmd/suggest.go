@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"context"
-	"huseynovvusal/gitai/internal/ai"
 	"huseynovvusal/gitai/internal/tui/suggest"
 
 	"github.com/spf13/cobra"
@@ -16,21 +15,28 @@ var suggestCmd = &cobra.Command{
 		rootCtx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		provStr := viper.GetString("ai.provider")
-		provider, err := ai.ParseProvider(provStr)
-		if err != nil {
-			cmd.PrintErrln("Invalid provider:", err)
+		cfg, ok := loadAIConfig(rootCtx, cmd)
+		if !ok {
+			return
+		}
+		if noCache, _ := cmd.Flags().GetBool("no-cache"); noCache {
+			cfg.Cache.Enabled = false
+		}
+		applyStyleFlag(cmd, cfg)
+		if !applyLangFlag(cmd, cfg) {
 			return
 		}
 
-		suggest.RunSuggestFlow(rootCtx, provider)
+		suggest.RunSuggestFlow(rootCtx, cfg)
 	},
 }
 
 func init() {
 	suggestCmd.Flags().StringP("provider", "p", "", "AI provider to use (gpt|gemini|ollama|geminicli). If empty, uses env or config or default")
-	suggestCmd.Flags().StringP("api_key", "k", "", "Optional API key to provide to AI provider")
+	suggestCmd.Flags().StringP("api_key", "k", "", "Optional API key for the selected provider; overrides config, environment and stored keys")
+	suggestCmd.Flags().Bool("no-cache", false, "Always ask the provider instead of reusing a cached message for the same diff")
+	addStyleFlag(suggestCmd)
+	addLangFlag(suggestCmd)
 	_ = viper.BindPFlag("ai.provider", suggestCmd.Flags().Lookup("provider"))
-	_ = viper.BindPFlag("ai.api_key", suggestCmd.Flags().Lookup("provider"))
 	rootCmd.AddCommand(suggestCmd)
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"huseynovvusal/gitai/internal/usage"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report provider token usage and estimated cost",
+	Long: `Usage totals the tokens and estimated cost of every provider call recorded
+in the local ledger (~/.local/share/gitai/usage.jsonl), grouped by day, repository,
+provider or model. Costs use list prices and may differ from your bill.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		by, _ := cmd.Flags().GetString("by")
+		format, _ := cmd.Flags().GetString("format")
+		days, _ := cmd.Flags().GetInt("days")
+
+		ledger, err := usage.Open()
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+
+		var since time.Time
+		if days > 0 {
+			y, m, d := time.Now().Date()
+			since = time.Date(y, m, d-days+1, 0, 0, 0, 0, time.Local)
+		}
+		records, err := ledger.Records(since)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+
+		rows, total, err := usage.Summarize(records, by)
+		if err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+		if err := usage.WriteReport(cmd.OutOrStdout(), format, by, rows, total); err != nil {
+			cmd.PrintErrln(err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	usageCmd.Flags().String("by", usage.ByDay, "Group totals by day, repo, provider or model")
+	usageCmd.Flags().StringP("format", "f", usage.FormatText, "Output format: text or json")
+	usageCmd.Flags().Int("days", 30, "Only include the last N days; 0 includes everything")
+	rootCmd.AddCommand(usageCmd)
+}
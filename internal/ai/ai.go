@@ -1,67 +1,79 @@
 package ai
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
-	"time"
 
 	geminicli "github.com/yubiquita/gemini-cli-wrapper"
 
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 	"github.com/openai/openai-go/v2/packages/param"
-	"github.com/spf13/viper"
 	"google.golang.org/genai"
 )
 
 const temperature = 0.7
 const maxToken = 256
 
-func CallGPT(ctx context.Context, systemMessage string, userMessage string, maxTokens int64, temperature float64) (string, error) {
-	// Prefer Viper-loaded key (config file, env, flags). Allow legacy OPENAI_API_KEY as fallback.
-	apiKey := viper.GetString("ai.api_key")
-	if apiKey == "" {
-		return "", ErrAPIKeyNotSet
+func CallGPT(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int64, temperature float64) (string, Usage, error) {
+	if cfg.OpenAI.APIKey == "" {
+		return "", Usage{}, ErrAPIKeyNotSet
 	}
 
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	// retries are handled by callWithRetry across all providers
+	opts := []option.RequestOption{option.WithAPIKey(cfg.OpenAI.APIKey), option.WithMaxRetries(0)}
+	if cfg.OpenAI.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(cfg.OpenAI.BaseURL))
+	}
+	client := openai.NewClient(opts...)
+
+	model := cfg.OpenAI.Model
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
 
-	res, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
-		Model: openai.ChatModelGPT3_5Turbo,
+	params := openai.ChatCompletionNewParams{
+		Model: model,
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(systemMessage),
 			openai.UserMessage(userMessage),
 		},
 		MaxTokens:   param.NewOpt(maxTokens),
 		Temperature: param.NewOpt(temperature),
-	})
+	}
+	if cfg.Output == OutputJSON {
+		params.ResponseFormat = openAIResponseFormat(model)
+	}
+
+	res, err := client.Chat.Completions.New(ctx, params)
 
 	if err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
 
 	if len(res.Choices) == 0 {
-		return "", ErrNoResponse
+		return "", Usage{}, ErrNoResponse
 	}
 
-	return res.Choices[0].Message.Content, nil
+	usage := Usage{PromptTokens: int(res.Usage.PromptTokens), CompletionTokens: int(res.Usage.CompletionTokens)}
+	return res.Choices[0].Message.Content, usage, nil
 
 }
 
-func CallGemini(ctx context.Context, systemMessage string, userMessage string, maxTokens int32, temperature float32) (string, error) {
-	apiKey := viper.GetString("ai.api_key")
-	if apiKey == "" {
-		return "", ErrAPIKeyNotSet
+func CallGemini(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int32, temperature float32) (string, Usage, error) {
+	if cfg.Gemini.APIKey == "" {
+		return "", Usage{}, ErrAPIKeyNotSet
 	}
 
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey: apiKey,
+		APIKey: cfg.Gemini.APIKey,
 	})
 	if err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
 
 	parts := []*genai.Part{
@@ -73,49 +85,79 @@ func CallGemini(ctx context.Context, systemMessage string, userMessage string, m
 		},
 	}
 	modelConfig := genai.GenerateContentConfig{Temperature: &temperature, MaxOutputTokens: maxTokens}
+	if cfg.Output == OutputJSON {
+		modelConfig.ResponseMIMEType = "application/json"
+		modelConfig.ResponseSchema = commitGeminiSchema
+	}
 
-	result, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", []*genai.Content{
+	model := cfg.Gemini.Model
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+
+	result, err := client.Models.GenerateContent(ctx, model, []*genai.Content{
 		{
 			Parts: parts,
 		},
 	}, &modelConfig)
 	if err != nil {
-		return "", err
+		return "", Usage{}, err
 	}
 
 	if len(result.Candidates) == 0 {
-		return "", ErrNoResponse
+		return "", Usage{}, ErrNoResponse
 	}
 
-	return result.Candidates[0].Content.Parts[0].Text, nil
+	var usage Usage
+	if md := result.UsageMetadata; md != nil {
+		usage = Usage{PromptTokens: int(md.PromptTokenCount), CompletionTokens: int(md.CandidatesTokenCount)}
+	}
+	return result.Candidates[0].Content.Parts[0].Text, usage, nil
 
 }
 
-func CallOllama(ctx context.Context, systemMessage string, userMessage string) (string, error) {
-	apiPath := viper.GetString("ollama.path")
+func CallOllama(ctx context.Context, cfg Config, systemMessage string, userMessage string) (string, error) {
+	apiPath := cfg.Ollama.Path
 
 	if apiPath == "" {
 		return "", ErrOllamaPathMissing
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	timeout := cfg.Ollama.Timeout
+	if timeout <= 0 {
+		timeout = DefaultOllamaTimeout
+	}
+	model := cfg.Ollama.Model
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	prompt := strings.Join([]string{systemMessage, userMessage}, "\n\n")
 
-	cmd := exec.CommandContext(ctx, apiPath, "run", "llama3.1:8b", prompt)
+	args := []string{"run", model, prompt}
+	if cfg.Output == OutputJSON {
+		args = []string{"run", "--format", "json", model, prompt}
+	}
+	cmd := exec.CommandContext(ctx, apiPath, args...)
 
-	out, err := cmd.CombinedOutput()
+	// ollama draws its spinner on stderr; only stdout is the answer
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
 
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-		return "", fmt.Errorf("ollama command timed out")
+		return "", fmt.Errorf("ollama command timed out: %w", context.DeadlineExceeded)
 	}
 
 	if err != nil {
-		return "", fmt.Errorf("ollama command failed: %v, output: %s", err, string(out))
+		return "", fmt.Errorf("ollama command failed: %v, output: %s", err, stripANSI(stderr.String()))
 	}
 
-	return string(out), nil
+	return stdout.String(), nil
 
 }
 
@@ -174,20 +216,90 @@ var callGemini = CallGemini
 var callOllama = CallOllama
 var callGeminiCLI = CallGeminiCLI
 
-func GenerateCommitMessage(ctx context.Context, provider Provider, diff string, status string) (string, error) {
-	userMessage := "diff: " + diff + "\n\nstatus: " + status
+// Generation is a generated commit message together with the call that
+// produced it; Provider differs from Config.Provider when a fallback answered.
+// Structured is set in OutputJSON mode, and Message is rendered from it.
+type Generation struct {
+	Message    string
+	Structured *CommitMessage
+	Call
+}
 
-	switch provider {
+// GenerateCommitMessage sends a rendered commit prompt (see PromptTemplates)
+// to the provider chain, prepared for cfg.Output, and cleans the answer with
+// CleanMessage or, in OutputJSON mode, parses it with ParseCommitMessage.
+func GenerateCommitMessage(ctx context.Context, cfg Config, prompt Prompt) (Generation, error) {
+	prompt = prompt.ForOutput(cfg.Output)
+	out, call, err := callChain(ctx, cfg, prompt.System, prompt.User, maxToken)
+	if err != nil {
+		return Generation{}, err
+	}
+	return newGeneration(cfg.Output, out, call)
+}
+
+func newGeneration(output Output, out string, call Call) (Generation, error) {
+	if output != OutputJSON {
+		return Generation{Message: CleanMessage(out), Call: call}, nil
+	}
+	m, err := ParseCommitMessage(out)
+	if err != nil {
+		return Generation{}, err
+	}
+	return Generation{Message: m.Render(), Structured: &m, Call: call}, nil
+}
+
+// RepairCommitMessage asks the provider chain to fix the listed problems of a
+// message it generated for prompt.
+func RepairCommitMessage(ctx context.Context, cfg Config, prompt Prompt, message string, problems []string) (Generation, error) {
+	var b strings.Builder
+	b.WriteString(prompt.User)
+	b.WriteString("\n\nYour previous answer was:\n")
+	b.WriteString(message)
+	b.WriteString("\n\nIt breaks these commit message rules:\n")
+	for _, p := range problems {
+		b.WriteString("- " + p + "\n")
+	}
+	if cfg.Output == OutputJSON {
+		b.WriteString("Answer again with the corrected JSON object only.")
+	} else {
+		b.WriteString("Answer again with the corrected message only.")
+	}
+
+	prompt = prompt.ForOutput(cfg.Output)
+	fixed, call, err := callChain(ctx, cfg, prompt.System, b.String(), maxToken)
+	if err != nil {
+		return Generation{}, err
+	}
+	return newGeneration(cfg.Output, fixed, call)
+}
+
+// callProvider dispatches a system/user message pair to the configured
+// provider. Usage is estimated when the provider does not report it.
+func callProvider(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int) (string, Usage, error) {
+	var (
+		out   string
+		usage Usage
+		err   error
+	)
+	switch cfg.Provider {
 	case ProviderGPT:
-		return callGPT(ctx, systemMessage, userMessage, maxToken, temperature)
+		out, usage, err = callGPT(ctx, cfg, systemMessage, userMessage, int64(maxTokens), temperature)
 	case ProviderGemini:
-		return callGemini(ctx, systemMessage, userMessage, maxToken, temperature)
+		out, usage, err = callGemini(ctx, cfg, systemMessage, userMessage, int32(maxTokens), temperature)
 	case ProviderOllama:
-		return callOllama(ctx, systemMessage, userMessage)
+		out, err = callOllama(ctx, cfg, systemMessage, userMessage)
 	case ProvideGeminiCLI:
-		return callGeminiCLI(systemMessage, userMessage)
+		out, err = callGeminiCLI(systemMessage, userMessage)
 
 	default:
-		return "", fmt.Errorf("invalid AI provider: %s", provider)
+		return "", Usage{}, fmt.Errorf("invalid AI provider: %s", cfg.Provider)
+	}
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	if usage.Total() == 0 {
+		usage = estimateUsage(systemMessage+"\n\n"+userMessage, out)
 	}
+	return out, usage, nil
 }
@@ -30,11 +30,11 @@ func TestGenerateCommitMessage_PropagatesError(t *testing.T) {
 	saved := callGemini
 	defer func() { callGemini = saved }()
 
-	callGemini = func(ctx context.Context, systemMessage string, userMessage string, maxTokens int32, temperature float32) (string, error) {
-		return "", ErrNoResponse
+	callGemini = func(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int32, temperature float32) (string, Usage, error) {
+		return "", Usage{}, ErrNoResponse
 	}
 
-	_, err := GenerateCommitMessage(context.Background(), ProviderGemini, "diff", "status")
+	_, err := GenerateCommitMessage(context.Background(), Config{Provider: ProviderGemini}, Prompt{System: "system", User: "diff"})
 	if err == nil {
 		t.Fatalf("expected error, got nil")
 	}
@@ -0,0 +1,261 @@
+package ai
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// cleanSteps turn raw model output into a bare commit message. Each step
+// expects the previous ones to have run: line endings are normalised first
+// so the others only deal with "\n".
+var cleanSteps = []func(string) string{
+	stripANSI,
+	normalizeLineEndings,
+	stripThinking,
+	extractFencedBlock,
+	stripPreamble,
+	stripTrailingCommentary,
+	stripQuotes,
+	normalizeBlankLines,
+}
+
+// CleanMessage strips what models and CLIs put around a commit message:
+// terminal escape sequences, reasoning blocks, code fences, chatty
+// preambles and trailing explanations, and quotes. It normalises line
+// endings and blank lines; wrapping the body to a width is left to WrapBody.
+func CleanMessage(raw string) string {
+	s := raw
+	for _, step := range cleanSteps {
+		s = step(s)
+	}
+	return s
+}
+
+var (
+	// ansiPattern matches CSI sequences (colours, cursor movement, the
+	// spinner of `ollama run`) and OSC sequences such as window titles.
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+	// spinnerPattern matches the braille spinner frames ollama draws.
+	spinnerPattern  = regexp.MustCompile(`[\x{2800}-\x{28FF}]`)
+	thinkingPattern = regexp.MustCompile(`(?s)<think(ing)?>.*?</think(ing)?>`)
+	fencePattern    = regexp.MustCompile("(?s)```[a-zA-Z0-9_-]*[ \t]*\n(.*?)\n[ \t]*```")
+	preamblePattern = regexp.MustCompile(`(?i)^[*_#\s]*(here('s| is| are)\b.*|sure\b.*|certainly\b.*|okay\b.*|based on the (diff|changes)\b.*|(the |suggested |proposed )?commit message\s*(:|is:?)?)[*_\s]*$`)
+	// commentaryPattern starts a paragraph that talks about the message
+	// rather than being part of it. It only matches phrases that refer to
+	// the message itself: a body may well start with "This commit adds".
+	commentaryPattern = regexp.MustCompile(`(?i)^[*_#\s]*(` +
+		`this commit message\b|` +
+		`this message (follows|uses|adheres to|summari[sz]es|captures|is written)\b|` +
+		`the (above|suggested|proposed) (commit )?message\b|` +
+		`th(is|e above) (follows|uses|adheres to) (the )?conventional commits?\b|` +
+		`explanation\s*:?[*_\s]*$|` +
+		`i hope (this|that|it) (helps|works|is helpful|meets)\b|` +
+		`let me know\b|` +
+		`feel free to (adjust|modify|change|edit|tweak)\b|` +
+		`i('ve| have) (used|followed|written|kept|made) (the|this|a) (subject|header|body|commit message|message|conventional commits?)\b)`)
+)
+
+// IsPreamble reports whether line is a lead-in such as "Here is your commit
+// message:" rather than part of the message.
+func IsPreamble(line string) bool {
+	return preamblePattern.MatchString(strings.TrimSpace(line))
+}
+
+func stripANSI(s string) string {
+	s = ansiPattern.ReplaceAllString(s, "")
+	return spinnerPattern.ReplaceAllString(s, "")
+}
+
+func normalizeLineEndings(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	// a bare CR redraws the line in a terminal; keep what was drawn last
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if j := strings.LastIndex(strings.TrimRight(l, "\r"), "\r"); j >= 0 {
+			l = l[j+1:]
+		}
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func stripThinking(s string) string {
+	return thinkingPattern.ReplaceAllString(s, "")
+}
+
+// extractFencedBlock keeps the content of the first non-empty fenced block:
+// text outside the fence is commentary. A lone unmatched fence line is dropped.
+func extractFencedBlock(s string) string {
+	for _, m := range fencePattern.FindAllStringSubmatch(s, -1) {
+		if strings.TrimSpace(m[1]) != "" {
+			return m[1]
+		}
+	}
+
+	var kept []string
+	for _, l := range strings.Split(s, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(l), "```") {
+			kept = append(kept, l)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func stripPreamble(s string) string {
+	lines := strings.Split(strings.TrimLeft(s, "\n"), "\n")
+	for len(lines) > 1 && (IsPreamble(lines[0]) || strings.TrimSpace(lines[0]) == "") {
+		lines = lines[1:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripTrailingCommentary cuts the message at the first paragraph after the
+// subject that talks about the message.
+func stripTrailingCommentary(s string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		startsParagraph := strings.TrimSpace(lines[i-1]) == ""
+		if startsParagraph && commentaryPattern.MatchString(lines[i]) {
+			return strings.Join(lines[:i], "\n")
+		}
+	}
+	return s
+}
+
+var quotePairs = [][2]string{{`"`, `"`}, {"'", "'"}, {"`", "`"}, {"“", "”"}, {"‘", "’"}}
+
+// stripQuotes removes quotes around the whole message or around the subject
+// line alone.
+func stripQuotes(s string) string {
+	t := strings.TrimSpace(s)
+	for _, q := range quotePairs {
+		if len(t) > len(q[0])+len(q[1]) && strings.HasPrefix(t, q[0]) && strings.HasSuffix(t, q[1]) {
+			inner := t[len(q[0]) : len(t)-len(q[1])]
+			// "a" and "b" is two quoted strings, not one
+			if !strings.Contains(inner, q[0]) && !strings.Contains(inner, q[1]) {
+				return inner
+			}
+		}
+	}
+
+	subject, rest, hasBody := strings.Cut(t, "\n")
+	for _, q := range quotePairs {
+		if len(subject) > len(q[0])+len(q[1]) && strings.HasPrefix(subject, q[0]) && strings.HasSuffix(subject, q[1]) {
+			subject = subject[len(q[0]) : len(subject)-len(q[1])]
+			break
+		}
+	}
+	if hasBody {
+		return subject + "\n" + rest
+	}
+	return subject
+}
+
+// normalizeBlankLines trims the message, collapses runs of blank lines and
+// separates the subject from the body with one blank line.
+func normalizeBlankLines(s string) string {
+	lines := strings.Split(strings.Trim(s, "\n"), "\n")
+	out := make([]string, 0, len(lines)+1)
+	for i, l := range lines {
+		if l == "" && len(out) > 0 && out[len(out)-1] == "" {
+			continue
+		}
+		if i == 1 && l != "" {
+			out = append(out, "")
+		}
+		out = append(out, l)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+var (
+	bulletPattern = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)`)
+	// trailerPattern matches git trailers such as Signed-off-by, which are
+	// never wrapped.
+	trailerPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: `)
+)
+
+// IsTrailer reports whether line looks like a git trailer such as
+// "Signed-off-by: x". Whether it is one depends on where it is; see
+// TrailerStart.
+func IsTrailer(line string) bool {
+	return trailerPattern.MatchString(line)
+}
+
+// TrailerStart returns the index of the first line of the trailer block of
+// a message split into lines, or len(lines) if it has none. Like git, only a
+// final paragraph after the subject whose lines all look like trailers is
+// one, so a body line such as "Note: ..." is prose.
+func TrailerStart(lines []string) int {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	start := end
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	// the first paragraph is the subject
+	if start == 0 || start == end {
+		return len(lines)
+	}
+	for _, l := range lines[start:end] {
+		if !IsTrailer(l) {
+			return len(lines)
+		}
+	}
+	return start
+}
+
+// WrapBody wraps the body lines of msg that exceed width at spaces,
+// indenting the continuation of a list item under its text. The subject,
+// trailers and words longer than width, such as URLs, are left intact.
+func WrapBody(msg string, width int) string {
+	if width <= 0 {
+		return msg
+	}
+	lines := strings.Split(msg, "\n")
+	trailers := TrailerStart(lines)
+	out := lines[:1:1]
+	for i, l := range lines[1:] {
+		if i+1 >= trailers {
+			out = append(out, l)
+			continue
+		}
+		out = append(out, wrapLine(l, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+func wrapLine(line string, width int) []string {
+	if utf8.RuneCountInString(line) <= width {
+		return []string{line}
+	}
+
+	prefix := bulletPattern.FindString(line)
+	if prefix == "" {
+		prefix = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	}
+	indent := strings.Repeat(" ", utf8.RuneCountInString(prefix))
+
+	var out []string
+	cur := prefix
+	curLen := utf8.RuneCountInString(prefix)
+	empty := true
+	for _, word := range strings.Fields(line[len(prefix):]) {
+		n := utf8.RuneCountInString(word)
+		if !empty && curLen+1+n > width {
+			out = append(out, cur)
+			cur, curLen, empty = indent, len(indent), true
+		}
+		if !empty {
+			cur += " "
+			curLen++
+		}
+		cur += word
+		curLen += n
+		empty = false
+	}
+	return append(out, cur)
+}
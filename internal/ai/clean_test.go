@@ -0,0 +1,147 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCleanMessage runs CleanMessage over raw provider outputs captured in
+// testdata/clean: each name.raw must clean to name.want, minus the final
+// newline.
+func TestCleanMessage(t *testing.T) {
+	raws, err := filepath.Glob(filepath.Join("testdata", "clean", "*.raw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raws) == 0 {
+		t.Fatal("no fixtures in testdata/clean")
+	}
+
+	for _, path := range raws {
+		name := strings.TrimSuffix(filepath.Base(path), ".raw")
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(strings.TrimSuffix(path, ".raw") + ".want")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := CleanMessage(string(raw))
+			if got != strings.TrimSuffix(string(want), "\n") {
+				t.Errorf("CleanMessage() =\n%s\n--- want ---\n%s", got, want)
+			}
+			if again := CleanMessage(got); again != got {
+				t.Errorf("CleanMessage is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestCleanMessage_Empty(t *testing.T) {
+	for _, raw := range []string{"", "\n\n", "```\n```", "\x1b[?25l\x1b[?25h"} {
+		if got := CleanMessage(raw); got != "" {
+			t.Errorf("CleanMessage(%q) = %q, want empty", raw, got)
+		}
+	}
+}
+
+func TestIsPreamble(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"Here is the commit message:", true},
+		{"**Commit message:**", true},
+		{"Sure, here you go.", true},
+		{"Based on the diff, this change adds caching.", true},
+		{"feat: add cache", false},
+		{"Here-doc support for prompts", false},
+	}
+	for _, tt := range tests {
+		if got := IsPreamble(tt.line); got != tt.want {
+			t.Errorf("IsPreamble(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestWrapBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   string
+		width int
+		want  string
+	}{
+		{
+			name:  "subject is never wrapped",
+			msg:   "feat: a subject that is longer than the width",
+			width: 20,
+			want:  "feat: a subject that is longer than the width",
+		},
+		{
+			name:  "paragraph",
+			msg:   "fix: x\n\none two three four five six",
+			width: 14,
+			want:  "fix: x\n\none two three\nfour five six",
+		},
+		{
+			name:  "list item continuation is indented",
+			msg:   "fix: x\n\n- one two three four",
+			width: 12,
+			want:  "fix: x\n\n- one two\n  three four",
+		},
+		{
+			name:  "long words and trailers are kept",
+			msg:   "fix: x\n\nsee https://example.com/a/very/long/url\n\nCo-authored-by: Someone With A Long Name <someone@example.com>",
+			width: 20,
+			want:  "fix: x\n\nsee\nhttps://example.com/a/very/long/url\n\nCo-authored-by: Someone With A Long Name <someone@example.com>",
+		},
+		{
+			name:  "trailer-like body lines are wrapped",
+			msg:   "fix: x\n\nNote: one two three four\n\nRefs: ABC-1",
+			width: 20,
+			want:  "fix: x\n\nNote: one two three\nfour\n\nRefs: ABC-1",
+		},
+		{
+			name:  "zero width disables wrapping",
+			msg:   "fix: x\n\none two three four five six",
+			width: 0,
+			want:  "fix: x\n\none two three four five six",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapBody(tt.msg, tt.width); got != tt.want {
+				t.Errorf("WrapBody() =\n%s\n--- want ---\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrailerStart(t *testing.T) {
+	const none = -1
+	tests := []struct {
+		msg  string
+		want int
+	}{
+		{"fix: x\n\nbody\n\nRefs: ABC-1\nSigned-off-by: A <a@example.com>", 4},
+		{"fix: x\n\nRefs: ABC-1\n", 2},
+		{"fix: x\n\nNote: the old flag still works.\n\nMore prose.", none},
+		{"fix: x\n\nNote: prose\nthat continues", none},
+		{"Refs: ABC-1", none},
+	}
+	for _, tt := range tests {
+		lines := strings.Split(tt.msg, "\n")
+		want := tt.want
+		if want == none {
+			want = len(lines)
+		}
+		if got := TrailerStart(lines); got != want {
+			t.Errorf("TrailerStart(%q) = %d, want %d", tt.msg, got, want)
+		}
+	}
+}
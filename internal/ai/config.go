@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// Config carries everything the AI layer needs to call a provider. It is
+// built from the typed application config; the ai package never reads viper.
+type Config struct {
+	Provider Provider
+	// Fallback providers are tried in order when Provider fails.
+	Fallback []Provider
+	Retry    RetryConfig
+	// Output selects free text or structured JSON answers for commit messages.
+	Output Output
+	OpenAI OpenAIConfig
+	Gemini GeminiConfig
+	Ollama OllamaConfig
+	// ResolveKey returns the API key of a fallback provider when the chain
+	// first reaches it, so a broken or slow key command of a fallback
+	// neither delays nor stops a call the primary answers. Nil keeps the
+	// configured keys.
+	ResolveKey func(ctx context.Context, p Provider) (string, error)
+}
+
+// Credentials hold a hosted provider's API key. APIKeyCommand is run by the
+// config layer, which stores its output in APIKey; the ai package only reads APIKey.
+type Credentials struct {
+	APIKey        string `mapstructure:"api_key"`
+	APIKeyCommand string `mapstructure:"api_key_command"`
+}
+
+type OpenAIConfig struct {
+	Credentials `mapstructure:",squash"`
+	Model       string `mapstructure:"model"`
+	// BaseURL points the client at an OpenAI compatible gateway; empty uses api.openai.com.
+	BaseURL string `mapstructure:"base_url"`
+}
+
+type GeminiConfig struct {
+	Credentials `mapstructure:",squash"`
+	Model       string `mapstructure:"model"`
+}
+
+type OllamaConfig struct {
+	Path    string        `mapstructure:"path"`
+	Model   string        `mapstructure:"model"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Model returns the effective model of the selected provider; the Gemini CLI
+// picks its own.
+func (c Config) Model() string {
+	switch c.Provider {
+	case ProviderGPT:
+		return orDefault(c.OpenAI.Model, DefaultOpenAIModel)
+	case ProviderGemini:
+		return orDefault(c.Gemini.Model, DefaultGeminiModel)
+	case ProviderOllama:
+		return orDefault(c.Ollama.Model, DefaultOllamaModel)
+	default:
+		return ""
+	}
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// Defaults used when the config leaves a setting empty.
+const (
+	DefaultOpenAIModel   = "gpt-3.5-turbo"
+	DefaultGeminiModel   = "gemini-2.0-flash"
+	DefaultOllamaModel   = "llama3.1:8b"
+	DefaultOllamaTimeout = 60 * time.Second
+)
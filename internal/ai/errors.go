@@ -9,4 +9,5 @@ var (
 	ErrAPIKeyNotSet      = errors.New("API key not set")
 	ErrNoResponse        = errors.New("no response from OpenAI")
 	ErrOllamaPathMissing = fmt.Errorf("ollama binary not found in PATH")
+	ErrMalformedResponse = errors.New("malformed response from AI provider")
 )
@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+)
+
+// Language is a language commit messages can be written in.
+type Language struct {
+	// Code is the ISO 639-1 code, e.g. "de"; it names prompt variants.
+	Code string
+	// Name is the English name used in prompts, e.g. "German".
+	Name string
+}
+
+// languages are the languages with a built-in instruction in lang/, written
+// in the language itself; other languages get a generic English one.
+var languages = []Language{
+	{"en", "English"},
+	{"de", "German"},
+	{"ja", "Japanese"},
+	{"fr", "French"},
+	{"es", "Spanish"},
+}
+
+//go:embed lang/*.md
+var languageInstructions embed.FS
+
+// ParseLanguage accepts a code ("de", "de-DE", "ja_JP") or an English name
+// ("German") and returns the language; "" means English, the default
+// prompt. An unknown name is kept as given so any language can be asked for.
+func ParseLanguage(s string) (Language, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Language{Code: "en", Name: "English"}, nil
+	}
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || strings.ContainsAny(s, "\n{}/") {
+		return Language{}, fmt.Errorf("invalid language %q", s)
+	}
+	code := strings.ToLower(parts[0])
+	for _, l := range languages {
+		if code == l.Code || strings.EqualFold(s, l.Name) {
+			return l, nil
+		}
+	}
+	return Language{Code: code, Name: s}, nil
+}
+
+// IsEnglish reports whether l needs no language instruction.
+func (l Language) IsEnglish() bool {
+	return l.Code == "" || l.Code == "en"
+}
+
+// Instruction tells the model to write in l while keeping trailer keys in
+// English and, for a Conventional Commits format, the type, scope and
+// "BREAKING CHANGE" too; it is empty for English. The files in lang/ hold
+// the general paragraph first and the Conventional Commits one second.
+func (l Language) Instruction(conventional bool) string {
+	if l.IsEnglish() {
+		return ""
+	}
+	general := fmt.Sprintf("Write the commit subject and body in %s. Keep trailer keys such as Signed-off-by in English.", l.Name)
+	keywords := "Also keep the Conventional Commits type (feat, fix, docs, refactor, ...), the scope and \"BREAKING CHANGE\" in English."
+	if data, err := languageInstructions.ReadFile("lang/" + l.Code + ".md"); err == nil {
+		general, keywords, _ = strings.Cut(strings.TrimSpace(string(data)), "\n\n")
+	}
+	if !conventional {
+		return strings.TrimSpace(general)
+	}
+	return strings.TrimSpace(general) + "\n" + strings.TrimSpace(keywords)
+}
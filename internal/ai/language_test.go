@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantCode string
+		wantName string
+	}{
+		{"", "en", "English"},
+		{"de", "de", "German"},
+		{"de-AT", "de", "German"},
+		{"ja_JP", "ja", "Japanese"},
+		{"japanese", "ja", "Japanese"},
+		{"Klingon", "klingon", "Klingon"},
+	}
+	for _, tt := range tests {
+		l, err := ParseLanguage(tt.in)
+		if err != nil {
+			t.Fatalf("ParseLanguage(%q): %v", tt.in, err)
+		}
+		if l.Code != tt.wantCode || l.Name != tt.wantName {
+			t.Errorf("ParseLanguage(%q) = %+v, want %s/%s", tt.in, l, tt.wantCode, tt.wantName)
+		}
+	}
+	for _, in := range []string{"-", "de/../x", "{{.Diff}}"} {
+		if _, err := ParseLanguage(in); err == nil {
+			t.Errorf("ParseLanguage(%q) succeeded", in)
+		}
+	}
+}
+
+func TestLanguageInstruction(t *testing.T) {
+	en, _ := ParseLanguage("en")
+	if got := en.Instruction(true); got != "" {
+		t.Errorf("English instruction = %q, want none", got)
+	}
+
+	other, _ := ParseLanguage("Klingon")
+	if got := other.Instruction(true); !strings.Contains(got, "in Klingon") {
+		t.Errorf("generic instruction = %q", got)
+	}
+
+	for _, l := range append(languages, other) {
+		if l.IsEnglish() {
+			continue
+		}
+		// a Conventional Commits format keeps the type keywords in English
+		got := l.Instruction(true)
+		if !strings.Contains(got, "feat") || !strings.Contains(got, "BREAKING CHANGE") || !strings.Contains(got, "Signed-off-by") {
+			t.Errorf("%s instruction does not keep the keywords in English:\n%s", l.Name, got)
+		}
+		// other formats have no type to keep
+		got = l.Instruction(false)
+		if strings.Contains(got, "feat") || strings.Contains(got, "Conventional Commits") || !strings.Contains(got, "Signed-off-by") {
+			t.Errorf("%s instruction for other formats mentions Conventional Commits:\n%s", l.Name, got)
+		}
+	}
+}
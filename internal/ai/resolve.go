@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"huseynovvusal/gitai/internal/conflict"
+)
+
+//go:embed resolve_prompt.md
+var resolveSystemMessage string
+
+const resolveMaxToken = 1024
+
+// ConflictResolution is the model's proposal for a single conflict hunk.
+type ConflictResolution struct {
+	Resolution string
+	Rationale  string
+	Call
+}
+
+// ResolveConflict asks the provider for a merged version of a conflict hunk
+// together with a short rationale.
+func ResolveConflict(ctx context.Context, cfg Config, path string, hunk *conflict.Hunk) (ConflictResolution, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "file: %s\n\n", path)
+	fmt.Fprintf(&b, "ours (%s):\n%s\n", hunk.OursLabel, hunk.Ours)
+	if hunk.Base != "" {
+		fmt.Fprintf(&b, "base:\n%s\n", hunk.Base)
+	}
+	fmt.Fprintf(&b, "theirs (%s):\n%s", hunk.TheirsLabel, hunk.Theirs)
+
+	out, call, err := callChain(ctx, cfg, resolveSystemMessage, b.String(), resolveMaxToken)
+	if err != nil {
+		return ConflictResolution{}, err
+	}
+
+	res, err := parseConflictResolution(out)
+	if err != nil {
+		return ConflictResolution{}, err
+	}
+	res.Call = call
+
+	// keep the hunk's trailing newline so the rendered file stays well-formed
+	if strings.HasSuffix(hunk.Ours, "\n") && !strings.HasSuffix(res.Resolution, "\n") {
+		res.Resolution += "\n"
+	}
+
+	return res, nil
+}
+
+// parseConflictResolution extracts the RATIONALE and RESOLUTION sections
+// from a model response.
+func parseConflictResolution(out string) (ConflictResolution, error) {
+	const rationaleTag, resolutionTag = "RATIONALE:", "RESOLUTION:"
+
+	idx := strings.Index(out, resolutionTag)
+	if idx < 0 {
+		return ConflictResolution{}, fmt.Errorf("%w: missing %s section", ErrMalformedResponse, resolutionTag)
+	}
+
+	rationale := out[:idx]
+	if r := strings.Index(rationale, rationaleTag); r >= 0 {
+		rationale = rationale[r+len(rationaleTag):]
+	}
+
+	// only trim blank lines so the indentation of the merged code survives
+	resolution := strings.Trim(stripCodeFence(out[idx+len(resolutionTag):]), "\r\n")
+
+	return ConflictResolution{
+		Resolution: resolution,
+		Rationale:  strings.TrimSpace(rationale),
+	}, nil
+}
+
+// stripCodeFence removes a surrounding ``` fence, which models tend to add
+// despite being told not to.
+func stripCodeFence(s string) string {
+	t := strings.TrimLeft(s, " \t\r\n")
+	if !strings.HasPrefix(t, "```") {
+		return s
+	}
+	nl := strings.Index(t, "\n")
+	if nl < 0 {
+		return s
+	}
+	t = strings.TrimRight(t[nl+1:], " \t\r\n")
+	return strings.TrimSuffix(t, "```")
+}
@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"google.golang.org/genai"
+)
+
+// RetryConfig controls how often a provider is retried on transient errors
+// before the next provider in the chain is tried.
+type RetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+}
+
+// Defaults used when RetryConfig leaves a setting empty.
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff     = 8 * time.Second
+)
+
+// sleep waits for d or until ctx is done; tests replace it to avoid delays.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// chain returns the primary provider followed by the fallbacks, without duplicates.
+func (c Config) chain() []Provider {
+	seen := map[Provider]bool{}
+	var out []Provider
+	for _, p := range append([]Provider{c.Provider}, c.Fallback...) {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// callChain tries each provider of cfg's chain in order, retrying transient
+// errors, and reports the call that answered. The returned error joins the
+// last error of every provider tried.
+func callChain(ctx context.Context, cfg Config, systemMessage, userMessage string, maxTokens int) (string, Call, error) {
+	var errs []error
+	for i, p := range cfg.chain() {
+		c := cfg
+		c.Provider = p
+		if i > 0 {
+			if err := c.resolveKey(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p, err))
+				continue
+			}
+		}
+
+		start := time.Now()
+		out, usage, err := callWithRetry(ctx, c, systemMessage, userMessage, maxTokens)
+		if err == nil {
+			return out, Call{Provider: p, Model: c.Model(), Usage: usage, Latency: time.Since(start)}, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p, err))
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return "", Call{}, errors.Join(errs...)
+}
+
+// resolveKey fills in the key of c.Provider through c.ResolveKey.
+func (c *Config) resolveKey(ctx context.Context) error {
+	if c.ResolveKey == nil {
+		return nil
+	}
+	key, err := c.ResolveKey(ctx, c.Provider)
+	if err != nil {
+		return err
+	}
+	switch c.Provider {
+	case ProviderGPT:
+		c.OpenAI.APIKey = key
+	case ProviderGemini:
+		c.Gemini.APIKey = key
+	}
+	return nil
+}
+
+func callWithRetry(ctx context.Context, cfg Config, systemMessage, userMessage string, maxTokens int) (string, Usage, error) {
+	attempts := cfg.Retry.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		out, usage, err := callProvider(ctx, cfg, systemMessage, userMessage, maxTokens)
+		if err == nil || attempt >= attempts || !isRetryable(ctx, err) {
+			return out, usage, err
+		}
+		if err := sleep(ctx, backoff(cfg.Retry, attempt)); err != nil {
+			return "", Usage{}, err
+		}
+	}
+}
+
+// backoff returns the delay before retry number attempt: exponential growth
+// capped at MaxBackoff, with "equal jitter" so concurrent clients spread out.
+func backoff(rc RetryConfig, attempt int) time.Duration {
+	initial, limit := rc.InitialBackoff, rc.MaxBackoff
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+	if limit <= 0 {
+		limit = DefaultMaxBackoff
+	}
+
+	d := initial << (attempt - 1)
+	if d > limit || d <= 0 {
+		d = limit
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// isRetryable reports whether err is transient: HTTP 429 or 5xx, a network
+// timeout, or a provider deadline, as long as ctx itself is still live.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return retryableStatus(openaiErr.StatusCode)
+	}
+	var geminiErr genai.APIError
+	if errors.As(err, &geminiErr) {
+		return retryableStatus(geminiErr.Code)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	saved := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = saved })
+	return &slept
+}
+
+func TestGenerateCommitMessage_RetriesThenFallsBack(t *testing.T) {
+	slept := stubSleep(t)
+	savedGemini, savedOllama := callGemini, callOllama
+	t.Cleanup(func() { callGemini, callOllama = savedGemini, savedOllama })
+
+	geminiCalls := 0
+	callGemini = func(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int32, temperature float32) (string, Usage, error) {
+		geminiCalls++
+		return "", Usage{}, genai.APIError{Code: 429, Message: "rate limited"}
+	}
+	callOllama = func(ctx context.Context, cfg Config, systemMessage string, userMessage string) (string, error) {
+		return "feat: add thing", nil
+	}
+
+	cfg := Config{Provider: ProviderGemini, Fallback: []Provider{ProviderOllama}, Retry: RetryConfig{MaxAttempts: 3}}
+	gen, err := GenerateCommitMessage(context.Background(), cfg, Prompt{System: "system", User: "diff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gen.Provider != ProviderOllama || gen.Message != "feat: add thing" {
+		t.Errorf("got %+v, want message from ollama", gen)
+	}
+	// ollama reports no usage, so it is estimated
+	if !gen.Usage.Estimated || gen.Usage.PromptTokens == 0 || gen.Model != DefaultOllamaModel {
+		t.Errorf("call = %+v, want estimated usage for %s", gen.Call, DefaultOllamaModel)
+	}
+	if geminiCalls != 3 || len(*slept) != 2 {
+		t.Errorf("gemini called %d times with %d sleeps, want 3 and 2", geminiCalls, len(*slept))
+	}
+}
+
+func TestGenerateCommitMessage_NoRetryOnPermanentError(t *testing.T) {
+	stubSleep(t)
+	saved := callGemini
+	t.Cleanup(func() { callGemini = saved })
+
+	calls := 0
+	callGemini = func(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int32, temperature float32) (string, Usage, error) {
+		calls++
+		return "", Usage{}, genai.APIError{Code: 400, Message: "bad request"}
+	}
+
+	_, err := GenerateCommitMessage(context.Background(), Config{Provider: ProviderGemini}, Prompt{System: "system", User: "diff"})
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected the provider error to be preserved, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("called %d times, want 1", calls)
+	}
+}
+
+func TestGenerateCommitMessage_ResolvesFallbackKeysLazily(t *testing.T) {
+	stubSleep(t)
+	savedGPT, savedGemini, savedOllama := callGPT, callGemini, callOllama
+	t.Cleanup(func() { callGPT, callGemini, callOllama = savedGPT, savedGemini, savedOllama })
+
+	callOllama = func(ctx context.Context, cfg Config, systemMessage string, userMessage string) (string, error) {
+		return "", errors.New("ollama is not running")
+	}
+	callGPT = func(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int64, temperature float64) (string, Usage, error) {
+		t.Error("gpt was called although its key could not be resolved")
+		return "", Usage{}, nil
+	}
+	var geminiKey string
+	callGemini = func(ctx context.Context, cfg Config, systemMessage string, userMessage string, maxTokens int32, temperature float32) (string, Usage, error) {
+		geminiKey = cfg.Gemini.APIKey
+		return "fix: a", Usage{}, nil
+	}
+
+	var resolved []Provider
+	cfg := Config{
+		Provider: ProviderOllama,
+		Fallback: []Provider{ProviderGPT, ProviderGemini},
+		ResolveKey: func(ctx context.Context, p Provider) (string, error) {
+			resolved = append(resolved, p)
+			if p == ProviderGPT {
+				return "", errors.New("api_key_command failed")
+			}
+			return "gemini-key", nil
+		},
+	}
+	gen, err := GenerateCommitMessage(context.Background(), cfg, Prompt{System: "system", User: "diff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gen.Provider != ProviderGemini || geminiKey != "gemini-key" {
+		t.Errorf("answered by %s with key %q, want gemini with the resolved key", gen.Provider, geminiKey)
+	}
+	if len(resolved) != 2 {
+		t.Errorf("resolved keys for %v, want gpt and gemini", resolved)
+	}
+
+	// a primary that answers never resolves the fallback keys
+	resolved = nil
+	callOllama = func(ctx context.Context, cfg Config, systemMessage string, userMessage string) (string, error) {
+		return "fix: a", nil
+	}
+	if _, err := GenerateCommitMessage(context.Background(), cfg, Prompt{System: "system", User: "diff"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 0 {
+		t.Errorf("resolved keys for %v although the primary answered", resolved)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	ctx := context.Background()
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"429":        {genai.APIError{Code: 429}, true},
+		"503":        {genai.APIError{Code: 503}, true},
+		"401":        {genai.APIError{Code: 401}, false},
+		"deadline":   {errors.Join(errors.New("ollama command timed out"), context.DeadlineExceeded), true},
+		"no answer":  {ErrNoResponse, false},
+		"no api key": {ErrAPIKeyNotSet, false},
+	}
+	for name, c := range cases {
+		if got := isRetryable(ctx, c.err); got != c.want {
+			t.Errorf("%s: isRetryable = %v, want %v", name, got, c.want)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if isRetryable(cancelled, genai.APIError{Code: 503}) {
+		t.Error("must not retry once the caller's context is done")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	rc := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, full := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 20; i++ {
+			d := backoff(rc, attempt)
+			if d < full/2 || d > full {
+				t.Fatalf("attempt %d: backoff %s outside [%s, %s]", attempt, d, full/2, full)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/param"
+	"github.com/openai/openai-go/v2/shared"
+	"google.golang.org/genai"
+)
+
+// Output is the shape providers are asked to answer in.
+type Output string
+
+const (
+	// OutputText asks for the commit message as plain text.
+	OutputText Output = "text"
+	// OutputJSON asks for a CommitMessage object, using the provider's JSON
+	// mode where it has one, and renders the message from it.
+	OutputJSON Output = "json"
+)
+
+// ParseOutput parses ai.output; empty means OutputText.
+func ParseOutput(s string) (Output, error) {
+	switch Output(strings.ToLower(strings.TrimSpace(s))) {
+	case "", OutputText:
+		return OutputText, nil
+	case OutputJSON:
+		return OutputJSON, nil
+	default:
+		return OutputText, fmt.Errorf("unknown output %q (expected text|json)", s)
+	}
+}
+
+// CommitMessage is a commit message as structured output. Footers are git
+// trailers such as "Refs: ABC-1" or "BREAKING CHANGE: ...".
+type CommitMessage struct {
+	Type     string   `json:"type"`
+	Scope    string   `json:"scope"`
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body"`
+	Breaking bool     `json:"breaking"`
+	Footers  []string `json:"footers"`
+}
+
+// jsonInstruction is appended to the system prompt in OutputJSON mode. It
+// repeats the schema because not every provider enforces one.
+const jsonInstruction = `Answer with a single JSON object and nothing else, with these fields:
+- "type": the Conventional Commits type, e.g. "feat" or "fix"
+- "scope": the scope, or "" for none
+- "subject": the imperative summary, without type or scope
+- "body": the body as plain text, or ""
+- "breaking": true if the change breaks compatibility
+- "footers": git trailers such as "BREAKING CHANGE: ..." or "Refs: ABC-1", or []`
+
+// ForOutput returns p prepared for the given output: OutputJSON appends the
+// JSON instructions to the system message.
+func (p Prompt) ForOutput(o Output) Prompt {
+	if o == OutputJSON {
+		p.System = strings.TrimRight(p.System, "\n") + "\n\n" + jsonInstruction
+	}
+	return p
+}
+
+var (
+	typePattern      = regexp.MustCompile(`^[a-zA-Z]+$`)
+	jsonObjectPrefix = regexp.MustCompile(`(?s)^[^{]*`)
+)
+
+// ParseCommitMessage decodes a CommitMessage from a provider answer. Text
+// around the object, such as a code fence, is ignored. The type must be a
+// single word and the subject a non-empty single line.
+func ParseCommitMessage(raw string) (CommitMessage, error) {
+	s := jsonObjectPrefix.ReplaceAllString(stripANSI(raw), "")
+	if i := strings.LastIndex(s, "}"); i >= 0 {
+		s = s[:i+1]
+	}
+
+	var m CommitMessage
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return CommitMessage{}, fmt.Errorf("%w: not a JSON commit message: %v", ErrMalformedResponse, err)
+	}
+
+	m.Type = strings.ToLower(strings.TrimSpace(m.Type))
+	m.Scope = strings.TrimSpace(m.Scope)
+	m.Subject = strings.TrimSpace(m.Subject)
+	m.Body = strings.TrimSpace(m.Body)
+	switch {
+	case !typePattern.MatchString(m.Type):
+		return CommitMessage{}, fmt.Errorf("%w: type %q is not a single word", ErrMalformedResponse, m.Type)
+	case m.Subject == "" || strings.Contains(m.Subject, "\n"):
+		return CommitMessage{}, fmt.Errorf("%w: subject must be a single non-empty line", ErrMalformedResponse)
+	}
+	return m, nil
+}
+
+// Render formats m as a Conventional Commits message.
+func (m CommitMessage) Render() string {
+	var b strings.Builder
+	b.WriteString(m.Type)
+	if m.Scope != "" {
+		b.WriteString("(" + m.Scope + ")")
+	}
+	if m.Breaking {
+		b.WriteString("!")
+	}
+	b.WriteString(": " + m.Subject)
+
+	if m.Body != "" {
+		b.WriteString("\n\n" + m.Body)
+	}
+
+	var footers []string
+	for _, f := range m.Footers {
+		if f = strings.TrimSpace(f); f != "" {
+			footers = append(footers, f)
+		}
+	}
+	if len(footers) > 0 {
+		b.WriteString("\n\n" + strings.Join(footers, "\n"))
+	}
+	return b.String()
+}
+
+// commitJSONSchema is the CommitMessage schema for OpenAI structured
+// outputs. Strict mode needs every property required, so empty strings
+// stand for absent values.
+var commitJSONSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"type":     map[string]any{"type": "string"},
+		"scope":    map[string]any{"type": "string"},
+		"subject":  map[string]any{"type": "string"},
+		"body":     map[string]any{"type": "string"},
+		"breaking": map[string]any{"type": "boolean"},
+		"footers":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	},
+	"required":             []string{"type", "scope", "subject", "body", "breaking", "footers"},
+	"additionalProperties": false,
+}
+
+// commitGeminiSchema is the same schema for Gemini's responseSchema.
+var commitGeminiSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"type":     {Type: genai.TypeString},
+		"scope":    {Type: genai.TypeString},
+		"subject":  {Type: genai.TypeString},
+		"body":     {Type: genai.TypeString},
+		"breaking": {Type: genai.TypeBoolean},
+		"footers":  {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+	},
+	PropertyOrdering: []string{"type", "scope", "subject", "body", "breaking", "footers"},
+	Required:         []string{"type", "subject", "breaking"},
+}
+
+// jsonSchemaModels are the OpenAI model prefixes that accept a JSON schema;
+// older models and unknown gateways get plain JSON mode.
+var jsonSchemaModels = []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4"}
+
+func supportsJSONSchema(model string) bool {
+	for _, p := range jsonSchemaModels {
+		if strings.HasPrefix(model, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func openAIResponseFormat(model string) openai.ChatCompletionNewParamsResponseFormatUnion {
+	if !supportsJSONSchema(model) {
+		return openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &shared.ResponseFormatJSONObjectParam{}}
+	}
+	return openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+		JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+			Name:   "commit_message",
+			Schema: commitJSONSchema,
+			Strict: param.NewOpt(true),
+		},
+	}}
+}
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseCommitMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "bare object",
+			raw:  `{"type":"feat","scope":"cache","subject":"store messages on disk","body":"","breaking":false,"footers":[]}`,
+			want: "feat(cache): store messages on disk",
+		},
+		{
+			name: "fenced with body, breaking and footers",
+			raw:  "```json\n" + `{"type":"Refactor","scope":"","subject":"drop the v1 config keys","body":"Old keys were deprecated in 0.4.","breaking":true,"footers":["BREAKING CHANGE: ai_provider is now ai.provider","Refs: ABC-1",""]}` + "\n```",
+			want: "refactor!: drop the v1 config keys\n\nOld keys were deprecated in 0.4.\n\nBREAKING CHANGE: ai_provider is now ai.provider\nRefs: ABC-1",
+		},
+		{
+			name: "missing optional fields",
+			raw:  `Here you go: {"type":"fix","subject":"quote paths"}`,
+			want: "fix: quote paths",
+		},
+		{name: "free text", raw: "feat: add cache", wantErr: true},
+		{name: "truncated", raw: `{"type":"feat","subject":"add`, wantErr: true},
+		{name: "type with scope", raw: `{"type":"feat(x)","subject":"y"}`, wantErr: true},
+		{name: "empty subject", raw: `{"type":"feat","subject":" "}`, wantErr: true},
+		{name: "multi-line subject", raw: `{"type":"feat","subject":"a\nb"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ParseCommitMessage(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMalformedResponse) {
+					t.Fatalf("err = %v, want ErrMalformedResponse", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Render(); got != tt.want {
+				t.Errorf("Render() =\n%s\n--- want ---\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	for in, want := range map[string]Output{"": OutputText, "text": OutputText, " JSON ": OutputJSON} {
+		if got, err := ParseOutput(in); err != nil || got != want {
+			t.Errorf("ParseOutput(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseOutput("yaml"); err == nil {
+		t.Error("ParseOutput(yaml) succeeded")
+	}
+}
+
+func TestGenerateCommitMessage_JSON(t *testing.T) {
+	saved := callOllama
+	t.Cleanup(func() { callOllama = saved })
+
+	var system string
+	callOllama = func(ctx context.Context, cfg Config, systemMessage string, userMessage string) (string, error) {
+		system = systemMessage
+		return `{"type":"docs","scope":"readme","subject":"document ai.output","body":"","breaking":false,"footers":[]}`, nil
+	}
+
+	cfg := Config{Provider: ProviderOllama, Output: OutputJSON}
+	gen, err := GenerateCommitMessage(context.Background(), cfg, Prompt{System: "system", User: "diff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(system, "system\n\n") || !strings.Contains(system, `"footers"`) {
+		t.Errorf("system message = %q, want the JSON instructions appended", system)
+	}
+	if gen.Message != "docs(readme): document ai.output" || gen.Structured == nil || gen.Structured.Scope != "readme" {
+		t.Errorf("got %+v", gen)
+	}
+
+	callOllama = func(ctx context.Context, cfg Config, systemMessage string, userMessage string) (string, error) {
+		return "docs: document ai.output", nil
+	}
+	if _, err := GenerateCommitMessage(context.Background(), cfg, Prompt{System: "system", User: "diff"}); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("err = %v, want ErrMalformedResponse for a free-text answer", err)
+	}
+}
+
+func TestSupportsJSONSchema(t *testing.T) {
+	for model, want := range map[string]bool{"gpt-4o-mini": true, "gpt-5": true, "o3-mini": true, "gpt-3.5-turbo": false, "llama3": false} {
+		if got := supportsJSONSchema(model); got != want {
+			t.Errorf("supportsJSONSchema(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
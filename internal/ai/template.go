@@ -0,0 +1,110 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Prompt is a rendered system and user message pair.
+type Prompt struct {
+	System string
+	User   string
+}
+
+// PromptData holds the variables available to commit prompt templates.
+type PromptData struct {
+	Diff          string
+	Status        string
+	Branch        string
+	RecentCommits []string
+	Files         []string
+	Ticket        string
+	// FormatPrompt and FormatExamples describe the commit format when it is
+	// not the Conventional Commits format of the built-in system prompt.
+	FormatPrompt   string
+	FormatExamples []string
+	// Scope is the component all changed files belong to, if they share
+	// one, and Scopes every component they touch (see internal/scope).
+	Scope  string
+	Scopes []string
+	// StyleExamples are recent commit messages shown as few-shot examples,
+	// and StyleHints the conventions they share; both are empty unless
+	// prompt.style_examples is set.
+	StyleExamples []string
+	StyleHints    []string
+	// Language is the English name of the language to write in, empty for
+	// English, and LanguageInstruction the built-in instruction for it.
+	Language            string
+	LanguageInstruction string
+}
+
+// DefaultCommitTemplate renders the diff and status, preceded by the commit
+// format, the repository's style examples, the scope and the language
+// instruction when there are any.
+const DefaultCommitTemplate = `{{if .FormatPrompt}}{{.FormatPrompt}}
+Follow this format instead of the one described above. Examples:
+{{range .FormatExamples}}---
+{{.}}
+{{end}}---
+
+{{end}}{{if .StyleExamples}}This repository has its own commit style. Follow it instead of the format described above.
+{{range .StyleHints}}- {{.}}
+{{end}}Recent commit messages:
+{{range .StyleExamples}}---
+{{.}}
+{{end}}---
+
+{{end}}{{if .Scope}}Use the scope "{{.Scope}}".
+
+{{else if .Scopes}}The change touches these components: {{join ", " .Scopes}}. Use the one that covers it best as the scope.
+
+{{end}}{{if .LanguageInstruction}}{{.LanguageInstruction}}
+
+{{end}}diff: {{.Diff}}
+
+status: {{.Status}}`
+
+var templateFuncs = template.FuncMap{
+	"join": func(sep string, items []string) string { return strings.Join(items, sep) },
+	"trim": strings.TrimSpace,
+}
+
+// PromptTemplates render the system and user message of a commit prompt.
+type PromptTemplates struct {
+	system *template.Template
+	commit *template.Template
+}
+
+// NewPromptTemplates parses the system and commit templates; an empty text
+// selects the built-in one.
+func NewPromptTemplates(system, commit string) (*PromptTemplates, error) {
+	if system == "" {
+		system = systemMessage
+	}
+	if commit == "" {
+		commit = DefaultCommitTemplate
+	}
+
+	st, err := template.New("system").Funcs(templateFuncs).Parse(system)
+	if err != nil {
+		return nil, fmt.Errorf("system prompt template: %w", err)
+	}
+	ct, err := template.New("commit").Funcs(templateFuncs).Parse(commit)
+	if err != nil {
+		return nil, fmt.Errorf("commit prompt template: %w", err)
+	}
+	return &PromptTemplates{system: st, commit: ct}, nil
+}
+
+// Render executes both templates with data.
+func (t *PromptTemplates) Render(data PromptData) (Prompt, error) {
+	var system, user strings.Builder
+	if err := t.system.Execute(&system, data); err != nil {
+		return Prompt{}, fmt.Errorf("system prompt template: %w", err)
+	}
+	if err := t.commit.Execute(&user, data); err != nil {
+		return Prompt{}, fmt.Errorf("commit prompt template: %w", err)
+	}
+	return Prompt{System: system.String(), User: user.String()}, nil
+}
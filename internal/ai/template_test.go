@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptTemplates_Default(t *testing.T) {
+	tmpl, err := NewPromptTemplates("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := tmpl.Render(PromptData{Diff: "+x", Status: "M a.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.System != systemMessage {
+		t.Errorf("system = %q, want the embedded prompt", p.System)
+	}
+	if p.User != "diff: +x\n\nstatus: M a.go" {
+		t.Errorf("user = %q", p.User)
+	}
+}
+
+func TestPromptTemplates_Custom(t *testing.T) {
+	commit := `{{if .Ticket}}Ticket: {{.Ticket}}
+{{end}}Branch: {{.Branch}}
+Files: {{join ", " .Files}}
+{{range .RecentCommits}}- {{.}}
+{{end}}{{trim .Diff}}`
+
+	tmpl, err := NewPromptTemplates("Write a commit for {{.Branch}}.", commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := tmpl.Render(PromptData{
+		Diff:          "\n+x\n",
+		Branch:        "feature/ABC-1-login",
+		Ticket:        "ABC-1",
+		Files:         []string{"a.go", "b.go"},
+		RecentCommits: []string{"feat: one", "fix: two"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.System != "Write a commit for feature/ABC-1-login." {
+		t.Errorf("system = %q", p.System)
+	}
+	want := "Ticket: ABC-1\nBranch: feature/ABC-1-login\nFiles: a.go, b.go\n- feat: one\n- fix: two\n+x"
+	if p.User != want {
+		t.Errorf("user = %q, want %q", p.User, want)
+	}
+}
+
+func TestPromptTemplates_Errors(t *testing.T) {
+	if _, err := NewPromptTemplates("", "{{.Diff"); err == nil || !strings.Contains(err.Error(), "commit prompt template") {
+		t.Errorf("parse error = %v, want one naming the commit template", err)
+	}
+
+	tmpl, err := NewPromptTemplates("", "{{.Unknown}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmpl.Render(PromptData{}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestPromptTemplates_DefaultWithStyle(t *testing.T) {
+	tmpl, err := NewPromptTemplates("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := tmpl.Render(PromptData{
+		Diff:          "+x",
+		Status:        "M a.go",
+		StyleExamples: []string{"ABC-1 Add login", "ABC-2 Fix crash"},
+		StyleHints:    []string{"Subjects start with an issue key such as ABC-123"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- Subjects start with an issue key", "---\nABC-1 Add login\n---\nABC-2 Fix crash\n---\n\ndiff: +x"} {
+		if !strings.Contains(p.User, want) {
+			t.Errorf("user message lacks %q:\n%s", want, p.User)
+		}
+	}
+}
+
+func TestPromptTemplates_DefaultWithScope(t *testing.T) {
+	tmpl, err := NewPromptTemplates("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		data PromptData
+		want string
+	}{
+		{PromptData{Diff: "+x", Scope: "billing", Scopes: []string{"billing"}}, "Use the scope \"billing\".\n\ndiff: +x"},
+		{PromptData{Diff: "+x", Scopes: []string{"billing", "docs"}}, "components: billing, docs."},
+	}
+	for _, tt := range tests {
+		p, err := tmpl.Render(tt.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(p.User, tt.want) {
+			t.Errorf("user message lacks %q:\n%s", tt.want, p.User)
+		}
+	}
+}
+
+func TestPromptTemplates_DefaultWithFormat(t *testing.T) {
+	tmpl, err := NewPromptTemplates("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := tmpl.Render(PromptData{
+		Diff:           "+x",
+		FormatPrompt:   "Use the gitmoji format.",
+		FormatExamples: []string{"✨ Add dark mode", "🐛 Fix crash"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Use the gitmoji format.", "✨ Add dark mode\n", "🐛 Fix crash\n", "diff: +x"} {
+		if !strings.Contains(p.User, want) {
+			t.Errorf("user message lacks %q:\n%s", want, p.User)
+		}
+	}
+}
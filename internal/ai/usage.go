@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// Usage counts the tokens of one provider call. Estimated is set when the
+// provider did not report them and they were counted locally.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	Estimated        bool
+}
+
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Call describes the provider call behind a response, for usage accounting.
+type Call struct {
+	Provider Provider
+	Model    string
+	Usage    Usage
+	Latency  time.Duration
+}
+
+// encodingName is the tokenizer used for estimates. It matches recent OpenAI
+// models exactly and other models closely enough to estimate cost.
+const encodingName = "cl100k_base"
+
+// tokenizerWait bounds how long an estimate waits for the tokenizer, which
+// is downloaded once and cached on first use.
+const tokenizerWait = 2 * time.Second
+
+var (
+	tokenizerOnce sync.Once
+	tokenizerDone = make(chan struct{})
+	tokenizer     *tiktoken.Tiktoken
+)
+
+func loadTokenizer() *tiktoken.Tiktoken {
+	tokenizerOnce.Do(func() {
+		go func() {
+			defer close(tokenizerDone)
+			tokenizer, _ = tiktoken.GetEncoding(encodingName)
+		}()
+	})
+
+	select {
+	case <-tokenizerDone:
+		return tokenizer
+	case <-time.After(tokenizerWait):
+		return nil
+	}
+}
+
+// estimateTokens counts the tokens of s with tiktoken, or approximates them
+// as four bytes per token when the tokenizer is unavailable (e.g. offline).
+func estimateTokens(s string) int {
+	if s == "" {
+		return 0
+	}
+	if enc := loadTokenizer(); enc != nil {
+		return len(enc.EncodeOrdinary(s))
+	}
+	return (len(s) + 3) / 4
+}
+
+func estimateUsage(prompt, completion string) Usage {
+	return Usage{
+		PromptTokens:     estimateTokens(prompt),
+		CompletionTokens: estimateTokens(completion),
+		Estimated:        true,
+	}
+}
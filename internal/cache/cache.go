@@ -0,0 +1,240 @@
+// Package cache stores generated commit messages on disk so that re-running
+// gitai on the same change does not bill the provider again.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Enabled bool          `mapstructure:"enabled"`
+	TTL     time.Duration `mapstructure:"ttl"`
+	// MaxSizeMB caps the cache directory; the oldest entries are evicted first.
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+}
+
+// Defaults used when the config leaves a setting empty.
+const (
+	DefaultTTL       = 7 * 24 * time.Hour
+	DefaultMaxSizeMB = 10
+)
+
+// Entry is one cached response.
+type Entry struct {
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Cache is a directory of JSON entries named by key.
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+}
+
+// Dir returns the default cache directory, $XDG_CACHE_HOME/gitai/responses
+// on Linux and the platform equivalent elsewhere.
+func Dir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "gitai", "responses"), nil
+}
+
+// Open returns the cache in the default directory.
+func Open(cfg Config) (*Cache, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return New(dir, cfg), nil
+}
+
+// New returns a cache stored in dir; the directory is created on first Put.
+func New(dir string, cfg Config) *Cache {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	size := cfg.MaxSizeMB
+	if size <= 0 {
+		size = DefaultMaxSizeMB
+	}
+	return &Cache{dir: dir, ttl: ttl, maxSize: int64(size) << 20}
+}
+
+// Key derives a cache key from its parts, e.g. provider, model, prompt
+// version and the diff. Parts are length-prefixed so they cannot run together.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		_ = binary.Write(h, binary.LittleEndian, uint64(len(p)))
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// Get returns the entry for key unless it is missing, unreadable or expired.
+func (c *Cache) Get(key string) (Entry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return Entry{}, false
+	}
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil || time.Since(e.CreatedAt) > c.ttl {
+		_ = os.Remove(c.path(key))
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Put stores e under key and evicts the oldest entries beyond the size limit.
+func (c *Cache) Put(key string, e Entry) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	// write then rename so a concurrent Get never sees a partial entry
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return c.evict()
+}
+
+type file struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (c *Cache) files() ([]file, error) {
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []file
+	for _, de := range entries {
+		if de.IsDir() || !strings.HasSuffix(de.Name(), ".json") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{path: filepath.Join(c.dir, de.Name()), size: info.Size(), modTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+// evict removes expired entries, then the oldest ones until the cache fits.
+func (c *Cache) evict() error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	for _, f := range files {
+		if total <= c.maxSize && time.Since(f.modTime) <= c.ttl {
+			continue
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+	return nil
+}
+
+// Clear removes every entry and returns how many were removed.
+func (c *Cache) Clear() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Stats describes the cache contents.
+type Stats struct {
+	Dir     string
+	Entries int
+	Expired int
+	Bytes   int64
+	Limit   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+func (c *Cache) Stats() (Stats, error) {
+	s := Stats{Dir: c.dir, Limit: c.maxSize}
+
+	files, err := c.files()
+	if err != nil {
+		return s, err
+	}
+	for _, f := range files {
+		s.Entries++
+		s.Bytes += f.size
+		if time.Since(f.modTime) > c.ttl {
+			s.Expired++
+		}
+		if s.Oldest.IsZero() || f.modTime.Before(s.Oldest) {
+			s.Oldest = f.modTime
+		}
+		if f.modTime.After(s.Newest) {
+			s.Newest = f.modTime
+		}
+	}
+	return s, nil
+}
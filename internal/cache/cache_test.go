@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetPut(t *testing.T) {
+	c := New(t.TempDir(), Config{})
+
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("Get on an empty cache reported a hit")
+	}
+
+	want := Entry{Provider: "gpt", Model: "gpt-4o-mini", Message: "feat: add cache"}
+	if err := c.Put("k", want); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := c.Get("k")
+	if !ok {
+		t.Fatal("Get after Put reported a miss")
+	}
+	if got.Message != want.Message || got.Provider != want.Provider || got.Model != want.Model {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+	if got.CreatedAt.IsZero() {
+		t.Error("Put did not stamp CreatedAt")
+	}
+}
+
+func TestGet_Expired(t *testing.T) {
+	c := New(t.TempDir(), Config{TTL: time.Hour})
+
+	if err := c.Put("old", Entry{Message: "stale", CreatedAt: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("old"); ok {
+		t.Fatal("expired entry was returned")
+	}
+	if _, err := os.Stat(c.path("old")); !os.IsNotExist(err) {
+		t.Errorf("expired entry was not removed: %v", err)
+	}
+}
+
+func TestPut_EvictsOldest(t *testing.T) {
+	c := New(t.TempDir(), Config{})
+	// room for roughly two entries
+	c.maxSize = 2500
+
+	msg := strings.Repeat("x", 1000)
+	for i, key := range []string{"a", "b", "c"} {
+		if err := c.Put(key, Entry{Message: msg}); err != nil {
+			t.Fatal(err)
+		}
+		// make modification order unambiguous regardless of timestamp resolution
+		mod := time.Now().Add(time.Duration(i-3) * time.Minute)
+		if err := os.Chtimes(c.path(key), mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.evict(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("oldest entry survived eviction")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("entry %q was evicted", key)
+		}
+	}
+}
+
+func TestClearAndStats(t *testing.T) {
+	c := New(t.TempDir(), Config{})
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, Entry{Message: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Entries != 2 || s.Bytes == 0 {
+		t.Errorf("Stats = %+v, want 2 entries", s)
+	}
+
+	n, err := c.Clear()
+	if err != nil || n != 2 {
+		t.Fatalf("Clear = %d, %v; want 2, nil", n, err)
+	}
+	if s, _ := c.Stats(); s.Entries != 0 {
+		t.Errorf("%d entries left after Clear", s.Entries)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("gpt", "m", "diff") != Key("gpt", "m", "diff") {
+		t.Error("Key is not deterministic")
+	}
+	// length prefixes keep shifted boundaries apart
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key collides when parts shift")
+	}
+	if Key("gpt", "m", "diff") == Key("gemini", "m", "diff") {
+		t.Error("Key ignores the provider")
+	}
+}
@@ -1,32 +1,432 @@
 package config
 
 import (
-	"os"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/spf13/viper"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/cache"
+	"huseynovvusal/gitai/internal/credentials"
+	"huseynovvusal/gitai/internal/format"
+	"huseynovvusal/gitai/internal/lint"
+	"huseynovvusal/gitai/internal/pathmatch"
+	"huseynovvusal/gitai/internal/scope"
+	"huseynovvusal/gitai/internal/security"
+	"huseynovvusal/gitai/internal/trailer"
+	"huseynovvusal/gitai/internal/usage"
 )
 
 type AIConfig struct {
-	Provider string `yaml:"provider"`
-	APIKey   string `yaml:"api_key"`
+	Provider string `mapstructure:"provider"`
+	// APIKey and APIKeyCommand apply to any hosted provider whose own section
+	// (openai.api_key, gemini.api_key, ...) is empty.
+	APIKey        string `mapstructure:"api_key"`
+	APIKeyCommand string `mapstructure:"api_key_command"`
+	// CredentialStore is where `gitai config set-key` saves keys: keyring or file.
+	CredentialStore string `mapstructure:"credential_store"`
+	// Fallback lists providers tried in order when Provider fails.
+	Fallback []string       `mapstructure:"fallback"`
+	Retry    ai.RetryConfig `mapstructure:"retry"`
+	// Output is text or json; json asks providers for a structured message.
+	Output string `mapstructure:"output"`
+	// Language is the language commit messages are written in, e.g. "de"
+	// or "Japanese"; empty means English.
+	Language string `mapstructure:"language"`
+}
+
+type GitConfig struct {
+	// Remote is the remote pushed to after committing; empty uses git's default.
+	Remote string `mapstructure:"remote"`
+}
+
+type TUIConfig struct {
+	// Editor opens hunks for manual edits in `gitai resolve`; empty falls back to $VISUAL / $EDITOR.
+	Editor string `mapstructure:"editor"`
+}
+
+type PromptConfig struct {
+	// ExcludePaths are gitignore-style globs whose content is withheld from prompts.
+	ExcludePaths []string `mapstructure:"exclude_paths"`
+	// SystemTemplate and CommitTemplate are text/template files overriding
+	// the built-in prompt; see internal/prompt for the lookup order.
+	SystemTemplate string `mapstructure:"system_template"`
+	CommitTemplate string `mapstructure:"commit_template"`
+	// RecentCommits is how many commit subjects templates see as .RecentCommits.
+	RecentCommits int `mapstructure:"recent_commits"`
+	// StyleExamples is how many recent commit messages are sampled as
+	// few-shot examples of the repository's style; 0 disables it.
+	StyleExamples int `mapstructure:"style_examples"`
 }
 
+// Config is the typed gitai configuration, unmarshalled once from viper in
+// cmd and passed explicitly to the AI, git, and TUI layers.
 type Config struct {
-	AI AIConfig `yaml:"ai"`
+	// Profile is the applied profile, if any (see ApplyProfile).
+	Profile  string               `mapstructure:"profile"`
+	AI       AIConfig             `mapstructure:"ai"`
+	OpenAI   ai.OpenAIConfig      `mapstructure:"openai"`
+	Gemini   ai.GeminiConfig      `mapstructure:"gemini"`
+	Ollama   ai.OllamaConfig      `mapstructure:"ollama"`
+	Git      GitConfig            `mapstructure:"git"`
+	Security security.Config      `mapstructure:"security"`
+	Cache    cache.Config         `mapstructure:"cache"`
+	Usage    usage.Config         `mapstructure:"usage"`
+	Lint     lint.Config          `mapstructure:"lint"`
+	Format   format.Config        `mapstructure:"format"`
+	Scope    scope.Config         `mapstructure:"scope"`
+	Ticket   trailer.TicketConfig `mapstructure:"ticket"`
+	Trailers trailer.Config       `mapstructure:"trailers"`
+	TUI      TUIConfig            `mapstructure:"tui"`
+	Prompt   PromptConfig         `mapstructure:"prompt"`
+
+	provider ai.Provider
+	fallback []ai.Provider
+	output   ai.Output
+	language ai.Language
+	preset   format.Preset
+}
+
+// envAliases are environment variables honoured in addition to the
+// automatic GITAI_<SECTION>_<KEY> names, in order of precedence.
+var envAliases = map[string][]string{
+	"ollama.path":    {"OLLAMA_API_PATH"},
+	"ai.api_key":     {"GITAI_API_KEY"},
+	"openai.api_key": {"OPENAI_API_KEY"},
+	"gemini.api_key": {"GEMINI_API_KEY", "GOOGLE_API_KEY"},
 }
 
-func LoadConfig(path string) (*Config, error) {
-	f, err := os.Open(path)
+// SetDefaults registers every key with its default so that environment
+// variables are picked up by Unmarshal even without a config file, and binds
+// the environment aliases.
+func SetDefaults(v *viper.Viper) {
+	for key, names := range envAliases {
+		_ = v.BindEnv(append([]string{key}, names...)...)
+	}
+
+	v.SetDefault("profile", "")
+	v.SetDefault("ai.provider", "")
+	v.SetDefault("ai.fallback", []string{})
+	v.SetDefault("ai.retry.max_attempts", ai.DefaultMaxAttempts)
+	v.SetDefault("ai.retry.initial_backoff", ai.DefaultInitialBackoff)
+	v.SetDefault("ai.retry.max_backoff", ai.DefaultMaxBackoff)
+	v.SetDefault("ai.api_key", "")
+	v.SetDefault("ai.api_key_command", "")
+	v.SetDefault("ai.credential_store", "")
+	v.SetDefault("ai.output", string(ai.OutputText))
+	v.SetDefault("ai.language", "")
+	v.SetDefault("openai.api_key", "")
+	v.SetDefault("openai.api_key_command", "")
+	v.SetDefault("openai.model", ai.DefaultOpenAIModel)
+	v.SetDefault("openai.base_url", "")
+	v.SetDefault("gemini.api_key", "")
+	v.SetDefault("gemini.api_key_command", "")
+	v.SetDefault("gemini.model", ai.DefaultGeminiModel)
+	v.SetDefault("ollama.path", "")
+	v.SetDefault("ollama.model", ai.DefaultOllamaModel)
+	v.SetDefault("ollama.timeout", ai.DefaultOllamaTimeout)
+	v.SetDefault("git.remote", "")
+	v.SetDefault("security.policy", string(security.DefaultPolicy))
+	v.SetDefault("cache.enabled", true)
+	v.SetDefault("cache.ttl", cache.DefaultTTL)
+	v.SetDefault("cache.max_size_mb", cache.DefaultMaxSizeMB)
+	v.SetDefault("usage.enabled", true)
+	v.SetDefault("usage.prices", []map[string]any{})
+	v.SetDefault("lint.conventional", true)
+	v.SetDefault("lint.types", lint.DefaultTypes)
+	v.SetDefault("lint.scopes", []string{})
+	v.SetDefault("lint.max_subject_length", lint.DefaultMaxSubjectLength)
+	v.SetDefault("lint.body_wrap", lint.DefaultBodyWrap)
+	v.SetDefault("lint.on_violation", string(lint.ActionRepair))
+	v.SetDefault("lint.header_pattern", "")
+	v.SetDefault("format.preset", format.DefaultPreset)
+	v.SetDefault("format.presets", []map[string]any{})
+	v.SetDefault("scope.paths", []map[string]any{})
+	v.SetDefault("scope.auto", true)
+	v.SetDefault("scope.enforce", false)
+	v.SetDefault("ticket.pattern", trailer.DefaultTicketPattern)
+	v.SetDefault("ticket.format", trailer.DefaultTicketFormat)
+	v.SetDefault("ticket.policy", string(trailer.PolicyNone))
+	v.SetDefault("ticket.trailer", trailer.DefaultTicketTrailer)
+	v.SetDefault("trailers.co_authors", []string{})
+	v.SetDefault("trailers.sign_off", false)
+	v.SetDefault("tui.editor", "")
+	v.SetDefault("prompt.exclude_paths", []string{})
+	v.SetDefault("prompt.system_template", "")
+	v.SetDefault("prompt.commit_template", "")
+	v.SetDefault("prompt.recent_commits", 5)
+	v.SetDefault("prompt.style_examples", 0)
+}
+
+// LoadConfig unmarshals and validates the configuration held by v, and
+// merges the repository's security rules file.
+func LoadConfig(v *viper.Viper) (*Config, error) {
+	cfg, err := Decode(v)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
+	sec, err := security.MergeRepoRules(cfg.Security)
+	if err != nil {
+		return nil, fmt.Errorf("config: security: %w", err)
+	}
+	if err := sec.Validate(); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", security.RulesFileName, err)
+	}
+	cfg.Security = sec
+
+	return cfg, nil
+}
+
+// Decode unmarshals and validates v without reading any repository files.
+func Decode(v *viper.Viper) (*Config, error) {
 	var cfg Config
-	decoder := yaml.NewDecoder(f)
-	if err := decoder.Decode(&cfg); err != nil {
-		return nil, err
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
+
+// Validate checks every section and reports all problems at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	provider, err := ai.ParseProvider(c.AI.Provider)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("ai.provider: %w (expected gpt|gemini|ollama|geminicli)", err))
+	}
+	c.provider = provider
+
+	c.fallback = nil
+	for _, f := range c.AI.Fallback {
+		p, err := ai.ParseProvider(f)
+		if err != nil || p == ai.ProviderNone {
+			errs = append(errs, fmt.Errorf("ai.fallback: unknown provider %q (expected gpt|gemini|ollama|geminicli)", f))
+			continue
+		}
+		c.fallback = append(c.fallback, p)
+	}
+
+	output, err := ai.ParseOutput(c.AI.Output)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("ai.output: %w", err))
+	}
+	c.output = output
+
+	language, err := ai.ParseLanguage(c.AI.Language)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("ai.language: %w", err))
+	}
+	c.language = language
+
+	if c.AI.Retry.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("ai.retry.max_attempts: must not be negative"))
+	}
+	if c.AI.Retry.MaxBackoff > 0 && c.AI.Retry.InitialBackoff > c.AI.Retry.MaxBackoff {
+		errs = append(errs, fmt.Errorf("ai.retry.initial_backoff: %s exceeds max_backoff %s", c.AI.Retry.InitialBackoff, c.AI.Retry.MaxBackoff))
+	}
+
+	if _, err := credentials.ParseStore(c.AI.CredentialStore); err != nil {
+		errs = append(errs, fmt.Errorf("ai.credential_store: %w", err))
+	}
+
+	if c.OpenAI.BaseURL != "" && !strings.HasPrefix(c.OpenAI.BaseURL, "http://") && !strings.HasPrefix(c.OpenAI.BaseURL, "https://") {
+		errs = append(errs, fmt.Errorf("openai.base_url: %q is not an http(s) URL", c.OpenAI.BaseURL))
+	}
+
+	if c.Ollama.Timeout < 0 || (c.Ollama.Timeout > 0 && c.Ollama.Timeout < time.Second) {
+		errs = append(errs, fmt.Errorf("ollama.timeout: %s is too short (use e.g. 60s)", c.Ollama.Timeout))
+	}
+
+	if c.Prompt.RecentCommits < 0 {
+		errs = append(errs, fmt.Errorf("prompt.recent_commits: must not be negative"))
+	}
+	if c.Prompt.StyleExamples < 0 {
+		errs = append(errs, fmt.Errorf("prompt.style_examples: must not be negative"))
+	}
+	for i, p := range c.Prompt.ExcludePaths {
+		if err := pathmatch.Validate(strings.TrimPrefix(strings.TrimSpace(p), "!")); err != nil {
+			errs = append(errs, fmt.Errorf("prompt.exclude_paths[%d]: %w", i, err))
+		}
+	}
+
+	if c.Cache.TTL < 0 {
+		errs = append(errs, fmt.Errorf("cache.ttl: must not be negative"))
+	}
+	if c.Cache.MaxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("cache.max_size_mb: must not be negative"))
+	}
+
+	for i, p := range c.Usage.Prices {
+		if p.Model == "" || p.Input < 0 || p.Output < 0 {
+			errs = append(errs, fmt.Errorf("usage.prices[%d]: needs a model and non-negative input/output prices", i))
+		}
+	}
+
+	if err := c.Lint.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("lint: %w", err))
+	}
+
+	if err := c.Format.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("format: %w", err))
+	} else {
+		c.preset, _ = c.Format.Resolve()
+		if c.output == ai.OutputJSON && !c.preset.Conventional {
+			errs = append(errs, fmt.Errorf("ai.output: json renders Conventional Commits headers; the %s format is not one", c.preset.Name))
+		}
+	}
+
+	if err := c.Scope.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("scope: %w", err))
+	}
+
+	if err := c.Ticket.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("ticket: %w", err))
+	}
+	if err := c.Trailers.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trailers: %w", err))
+	}
+
+	if err := c.Security.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("security: %w", err))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+}
+
+// Provider returns the parsed ai.provider.
+func (c *Config) Provider() ai.Provider {
+	return c.provider
+}
+
+// Language returns the parsed ai.language.
+func (c *Config) Language() ai.Language {
+	return c.language
+}
+
+// Preset returns the commit format selected by format.preset.
+func (c *Config) Preset() format.Preset {
+	return c.preset
+}
+
+// LintConfig returns the lint section with the header rules of the format
+// preset applied.
+func (c *Config) LintConfig() lint.Config {
+	return c.preset.Lint(c.Lint)
+}
+
+// SetLanguage overrides ai.language, e.g. from --lang.
+func (c *Config) SetLanguage(s string) error {
+	lang, err := ai.ParseLanguage(s)
+	if err != nil {
+		return fmt.Errorf("ai.language: %w", err)
+	}
+	c.AI.Language, c.language = s, lang
+	return nil
+}
+
+// ScanProvider is the provider whose security rules a diff is checked against
+// before generation. Any provider in the fallback chain may receive the diff,
+// so the first one with PII rules enforced wins over a local primary.
+func (c *Config) ScanProvider() string {
+	for _, p := range append([]ai.Provider{c.provider}, c.fallback...) {
+		if c.Security.PIIEnforcedFor(string(p)) {
+			return string(p)
+		}
+	}
+	return string(c.provider)
+}
+
+// AIConfig returns the settings the AI layer needs for the selected provider.
+func (c *Config) AIConfig() ai.Config {
+	return ai.Config{
+		Provider: c.provider,
+		Fallback: c.fallback,
+		Retry:    c.AI.Retry,
+		Output:   c.output,
+		OpenAI:   c.OpenAI,
+		Gemini:   c.Gemini,
+		Ollama:   c.Ollama,
+		ResolveKey: func(ctx context.Context, p ai.Provider) (string, error) {
+			if err := c.resolveAPIKey(ctx, p); err != nil {
+				return "", err
+			}
+			if creds := c.credentials(p); creds != nil {
+				return creds.APIKey, nil
+			}
+			return "", nil
+		},
+	}
+}
+
+// credentials returns the key settings of a hosted provider, or nil for
+// providers that need no key.
+func (c *Config) credentials(p ai.Provider) *ai.Credentials {
+	switch p {
+	case ai.ProviderGPT:
+		return &c.OpenAI.Credentials
+	case ai.ProviderGemini:
+		return &c.Gemini.Credentials
+	default:
+		return nil
+	}
+}
+
+// SetAPIKey overrides the selected provider's key, e.g. from --api_key.
+func (c *Config) SetAPIKey(key string) {
+	if creds := c.credentials(c.provider); creds != nil {
+		creds.APIKey, creds.APIKeyCommand = key, ""
+	}
+}
+
+// ResolveAPIKey fills in the API key of the selected provider, trying in
+// order the provider's api_key, its api_key_command, ai.api_key,
+// ai.api_key_command and finally the credential store. A key wins over a
+// command at the same level so that OPENAI_API_KEY and friends, which are
+// bound to api_key, still override a command from a config file. Fallback keys are resolved
+// the same way through AIConfig().ResolveKey, only when a call falls back.
+func (c *Config) ResolveAPIKey(ctx context.Context) error {
+	return c.resolveAPIKey(ctx, c.provider)
+}
+
+func (c *Config) resolveAPIKey(ctx context.Context, p ai.Provider) error {
+	creds := c.credentials(p)
+	if creds == nil {
+		return nil
+	}
+
+	var err error
+	switch {
+	case creds.APIKey != "":
+	case creds.APIKeyCommand != "":
+		creds.APIKey, err = credentials.RunCommand(ctx, creds.APIKeyCommand)
+		// the key is resolved; don't run the command again for a duplicate in the chain
+		creds.APIKeyCommand = ""
+	case c.AI.APIKey != "":
+		creds.APIKey = c.AI.APIKey
+	case c.AI.APIKeyCommand != "":
+		creds.APIKey, err = credentials.RunCommand(ctx, c.AI.APIKeyCommand)
+	default:
+		store, _ := credentials.ParseStore(c.AI.CredentialStore)
+		creds.APIKey, err = credentials.Lookup(store, string(p))
+		if errors.Is(err, credentials.ErrNotFound) {
+			// reported by the provider call as ErrAPIKeyNotSet
+			err = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s API key: %w", p, err)
+	}
+	return nil
+}
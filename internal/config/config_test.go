@@ -0,0 +1,134 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"huseynovvusal/gitai/internal/ai"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveAPIKey(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{
+			name: "provider key beats shared key",
+			cfg:  Config{AI: AIConfig{Provider: "gpt", APIKey: "shared"}, OpenAI: ai.OpenAIConfig{Credentials: ai.Credentials{APIKey: "openai"}}},
+			want: "openai",
+		},
+		{
+			name: "provider key beats provider command",
+			cfg:  Config{AI: AIConfig{Provider: "gemini"}, Gemini: ai.GeminiConfig{Credentials: ai.Credentials{APIKey: "static", APIKeyCommand: "echo from-cmd"}}},
+			want: "static",
+		},
+		{
+			name: "provider command beats shared key",
+			cfg:  Config{AI: AIConfig{Provider: "gemini", APIKey: "shared"}, Gemini: ai.GeminiConfig{Credentials: ai.Credentials{APIKeyCommand: "printf 'from-cmd\\nmetadata'"}}},
+			want: "from-cmd",
+		},
+		{
+			name: "shared key beats shared command",
+			cfg:  Config{AI: AIConfig{Provider: "gpt", APIKey: "shared", APIKeyCommand: "echo shared-cmd"}},
+			want: "shared",
+		},
+		{
+			name: "shared command as last resort",
+			cfg:  Config{AI: AIConfig{Provider: "gemini", APIKeyCommand: "echo shared-cmd"}},
+			want: "shared-cmd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			if err := cfg.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.ResolveAPIKey(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			aiCfg := cfg.AIConfig()
+			got := aiCfg.OpenAI.APIKey
+			if aiCfg.Provider == ai.ProviderGemini {
+				got = aiCfg.Gemini.APIKey
+			}
+			if got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveAPIKey_CommandFailure(t *testing.T) {
+	cfg := Config{AI: AIConfig{Provider: "gpt"}, OpenAI: ai.OpenAIConfig{Credentials: ai.Credentials{APIKeyCommand: "exit 3"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ResolveAPIKey(context.Background()); err == nil {
+		t.Fatal("expected an error from a failing api_key_command")
+	}
+}
+
+func TestResolveAPIKey_FallbackCommandFailure(t *testing.T) {
+	cfg := Config{
+		AI:     AIConfig{Provider: "gpt", Fallback: []string{"gemini"}},
+		OpenAI: ai.OpenAIConfig{Credentials: ai.Credentials{APIKey: "sk-primary"}},
+		Gemini: ai.GeminiConfig{Credentials: ai.Credentials{APIKeyCommand: "exit 3"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// a broken fallback must not stop a call the primary can answer
+	if err := cfg.ResolveAPIKey(context.Background()); err != nil {
+		t.Fatalf("ResolveAPIKey() = %v, want the fallback left for later", err)
+	}
+	if _, err := cfg.AIConfig().ResolveKey(context.Background(), ai.ProviderGemini); err == nil {
+		t.Error("ResolveKey(gemini) succeeded with a failing api_key_command")
+	}
+}
+
+func TestSetDefaults_EnvAliases(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-openai")
+	t.Setenv("GEMINI_API_KEY", "gemini-key")
+	t.Setenv("GITAI_API_KEY", "shared")
+	t.Setenv("OLLAMA_API_PATH", "/opt/ollama")
+
+	v := viper.New()
+	SetDefaults(v)
+	cfg, err := Decode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OpenAI.APIKey != "sk-openai" || cfg.Gemini.APIKey != "gemini-key" || cfg.AI.APIKey != "shared" || cfg.Ollama.Path != "/opt/ollama" {
+		t.Errorf("env aliases not applied: openai %q, gemini %q, ai %q, ollama %q", cfg.OpenAI.APIKey, cfg.Gemini.APIKey, cfg.AI.APIKey, cfg.Ollama.Path)
+	}
+}
+
+func TestResolveAPIKey_EnvBeatsFileCommand(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+
+	v := viper.New()
+	SetDefaults(v)
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader("ai:\n  provider: gpt\nopenai:\n  api_key_command: echo from-file\n")); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Decode(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ResolveAPIKey(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.AIConfig().OpenAI.APIKey; got != "sk-env" {
+		t.Errorf("key = %q, want the OPENAI_API_KEY value", got)
+	}
+}
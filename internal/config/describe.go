@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Setting is one effective config value and where it came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Describe returns the effective value of every key in v together with its
+// source: an environment variable, the applied profile, the config file, or
+// the built-in default. Secrets are masked.
+func Describe(v *viper.Viper) []Setting {
+	profile := v.GetString("profile")
+
+	var settings []Setting
+	for _, key := range Keys() {
+		value := FormatValue(v.Get(key))
+		if IsSecretKey(key) {
+			value = MaskSecret(value)
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: source(v, key, profile)})
+	}
+	return settings
+}
+
+func source(v *viper.Viper, key, profile string) string {
+	for _, name := range EnvNames(key) {
+		if _, ok := os.LookupEnv(name); ok {
+			return "env " + name
+		}
+	}
+	if key == "profile" && profile != "" && !v.InConfig(key) {
+		return "selected by repository match or --profile"
+	}
+	if profile != "" && v.InConfig("profiles."+profile+"."+key) {
+		return "profile " + profile
+	}
+	if v.InConfig(key) {
+		return "file " + v.ConfigFileUsed()
+	}
+	return "default"
+}
+
+// EnvNames returns the environment variables that set key, in order of precedence.
+func EnvNames(key string) []string {
+	names := []string{"GITAI_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))}
+	return append(names, envAliases[key]...)
+}
+
+// IsSecretKey reports whether values of key must not be printed in full.
+func IsSecretKey(key string) bool {
+	last := key[strings.LastIndex(key, ".")+1:]
+	if last == "api_key" {
+		return true
+	}
+	for _, s := range []string{"token", "secret", "password"} {
+		if strings.Contains(last, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskSecret hides all but the last four characters of long secrets.
+func MaskSecret(s string) string {
+	switch {
+	case s == "":
+		return ""
+	case len(s) <= 8:
+		return "****"
+	default:
+		return "****" + s[len(s)-4:]
+	}
+}
+
+// FormatValue renders a config value on one line.
+func FormatValue(val interface{}) string {
+	switch t := val.(type) {
+	case nil:
+		return ""
+	case []string:
+		return "[" + strings.Join(t, ", ") + "]"
+	case []interface{}:
+		parts := make([]string, len(t))
+		for i, e := range t {
+			if _, ok := e.(map[string]interface{}); ok {
+				return fmt.Sprintf("(%d entries)", len(t))
+			}
+			parts[i] = fmt.Sprint(e)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the base name of config files, without extension.
+const FileName = "gitai"
+
+// UserConfigPath returns the per-user config file written by `gitai config init`.
+func UserConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gitai", FileName+".yaml"), nil
+}
+
+// RepoConfigPath returns the config file written by `gitai config set --repo`
+// for the repository at root.
+func RepoConfigPath(root string) string {
+	return filepath.Join(root, FileName+".yaml")
+}
+
+// Keys returns every leaf key of Config in sorted order. Lists of structs
+// (security.rules) and maps are reported as a single key.
+func Keys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(Config{}), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if opts == "squash" {
+			collectKeys(f.Type, prefix, keys)
+			continue
+		}
+		if !f.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + tag
+		if f.Type.Kind() == reflect.Struct && f.Type.String() != "time.Duration" {
+			collectKeys(f.Type, key+".", keys)
+			continue
+		}
+		*keys = append(*keys, key)
+	}
+}
+
+// IsKnownKey reports whether key is a config key, a key inside a profile,
+// or an element below a list or map key such as security.rules.
+func IsKnownKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasPrefix(key, "profiles.") {
+		parts := strings.SplitN(key, ".", 3)
+		if len(parts) < 3 {
+			return false
+		}
+		rest := parts[2]
+		return strings.HasPrefix(rest, "match.") || rest == "match" || IsKnownKey(rest)
+	}
+	for _, k := range Keys() {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// UnknownKeys returns the keys set in v that gitai does not recognise,
+// typically typos such as "ai.provder".
+func UnknownKeys(v *viper.Viper) []string {
+	var unknown []string
+	for _, k := range v.AllKeys() {
+		if !IsKnownKey(k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// KeyValue is a single assignment for SetInFile.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// SetInFile applies the assignments to the YAML config file at path, creating
+// the file and any intermediate sections. Values are parsed as YAML, so lists
+// can be written as "[a, b]". Comments and the order of existing keys are
+// kept. The result is validated before it is written.
+func SetInFile(path string, kvs ...KeyValue) error {
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return fmt.Errorf("%s: only YAML config files can be edited", path)
+	}
+
+	doc, err := readYAML(path)
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range kvs {
+		if !IsKnownKey(kv.Key) {
+			return fmt.Errorf("unknown config key %q", kv.Key)
+		}
+
+		var parsed yaml.Node
+		if err := yaml.Unmarshal([]byte(kv.Value), &parsed); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", kv.Key, err)
+		}
+		valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: ""}
+		if len(parsed.Content) > 0 {
+			valueNode = parsed.Content[0]
+		}
+		setNode(doc.Content[0], strings.Split(strings.ToLower(kv.Key), "."), valueNode)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(buf.Bytes())); err != nil {
+		return err
+	}
+	if _, err := Decode(v); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	// the file may hold API keys
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+func readYAML(path string) (*yaml.Node, error) {
+	empty := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return empty, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level is not a mapping", path)
+	}
+	return &doc, nil
+}
+
+// setNode sets path below the mapping node m, replacing scalars in the way.
+func setNode(m *yaml.Node, path []string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			old := m.Content[i+1]
+			value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			m.Content[i+1] = value
+			return
+		}
+		if m.Content[i+1].Kind != yaml.MappingNode {
+			m.Content[i+1] = &yaml.Node{Kind: yaml.MappingNode}
+		}
+		setNode(m.Content[i+1], path[1:], value)
+		return
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	if len(path) == 1 {
+		m.Content = append(m.Content, keyNode, value)
+		return
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, keyNode, child)
+	setNode(child, path[1:], value)
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetInFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitai.yaml")
+	orig := "# team defaults\nai:\n  provider: gemini # keep\n"
+	if err := os.WriteFile(path, []byte(orig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := SetInFile(path,
+		KeyValue{Key: "ai.provider", Value: "gpt"},
+		KeyValue{Key: "prompt.exclude_paths", Value: "[vendor/, '*.lock']"},
+		KeyValue{Key: "profiles.work.openai.base_url", Value: "https://gw.example.com/v1"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{"# team defaults", "provider: gpt # keep", "exclude_paths: [vendor/, '*.lock']", "base_url: https://gw.example.com/v1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("file missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSetInFile_Rejects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitai.yaml")
+
+	if err := SetInFile(path, KeyValue{Key: "ai.provder", Value: "gpt"}); err == nil {
+		t.Error("expected unknown key error")
+	}
+	if err := SetInFile(path, KeyValue{Key: "security.policy", Value: "shout"}); err == nil {
+		t.Error("expected validation error")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("invalid changes must not be written")
+	}
+}
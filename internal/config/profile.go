@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/pathmatch"
+)
+
+// ProfileMatch selects a profile automatically for repositories whose remote
+// URL or root directory matches one of the patterns.
+type ProfileMatch struct {
+	// Remotes are URL globs where "*" matches any characters, e.g.
+	// "*github.com/acme/*" or "git@git.corp.example.com:*".
+	Remotes []string `mapstructure:"remotes"`
+	// Paths are gitignore-style globs for the repository root; "~" expands to
+	// the home directory and a directory also matches everything below it.
+	Paths []string `mapstructure:"paths"`
+}
+
+// Repo describes the repository a profile is matched against.
+type Repo struct {
+	Root    string
+	Remotes []string
+}
+
+// CurrentRepo describes the repository in the working directory; outside a
+// repository it is empty and matches no profile.
+func CurrentRepo() Repo {
+	root, err := git.GetGitRoot()
+	if err != nil {
+		return Repo{}
+	}
+	remotes, _ := git.GetRemoteURLs()
+	return Repo{Root: root, Remotes: remotes}
+}
+
+// ApplyProfile merges the selected profile over the base configuration held
+// by v. The profile named by the "profile" key (--profile, GITAI_PROFILE or
+// the config file) wins; otherwise the first profile, in name order, whose
+// match patterns fit repo is used. Flags and environment variables still
+// override profile values. It returns the applied profile, or "" if none.
+func ApplyProfile(v *viper.Viper, repo Repo) (string, error) {
+	profiles := v.GetStringMap("profiles")
+
+	name := v.GetString("profile")
+	if name == "" {
+		var err error
+		name, err = matchProfile(v, profiles, repo)
+		if err != nil || name == "" {
+			return "", err
+		}
+	}
+
+	settings, ok := profiles[strings.ToLower(name)].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("profile %q is not defined (known: %s)", name, strings.Join(profileNames(profiles), ", "))
+	}
+
+	overlay := make(map[string]interface{}, len(settings))
+	for k, val := range settings {
+		if k != "match" {
+			overlay[k] = val
+		}
+	}
+	if err := v.MergeConfigMap(overlay); err != nil {
+		return "", fmt.Errorf("profile %q: %w", name, err)
+	}
+
+	v.Set("profile", name)
+	return name, nil
+}
+
+func matchProfile(v *viper.Viper, profiles map[string]interface{}, repo Repo) (string, error) {
+	if repo.Root == "" {
+		return "", nil
+	}
+
+	for _, name := range profileNames(profiles) {
+		var m ProfileMatch
+		if err := v.UnmarshalKey("profiles."+name+".match", &m); err != nil {
+			return "", fmt.Errorf("profiles.%s.match: %w", name, err)
+		}
+		if m.matches(repo) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+func (m ProfileMatch) matches(repo Repo) bool {
+	for _, pattern := range m.Remotes {
+		for _, url := range repo.Remotes {
+			if matchURL(pattern, url) {
+				return true
+			}
+		}
+	}
+
+	root := strings.TrimPrefix(filepath.ToSlash(repo.Root), "/")
+	for _, pattern := range m.Paths {
+		pattern = strings.TrimPrefix(filepath.ToSlash(ExpandHome(pattern)), "/")
+		// anchor the pattern so "work" does not match any directory named work
+		if pathmatch.Match("/"+pattern, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchURL(pattern, url string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	re, err := regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+	return err == nil && re.MatchString(url)
+}
+
+// ExpandHome replaces a leading ~ in a configured path with the home directory.
+func ExpandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
+
+func profileNames(profiles map[string]interface{}) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
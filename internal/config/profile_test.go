@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const profilesYAML = `
+ai:
+  provider: gemini
+openai:
+  model: gpt-4o
+profiles:
+  work:
+    match:
+      remotes: ["*github.com/acme/*", "git@git.corp.example.com:*"]
+    ai:
+      provider: gpt
+    openai:
+      base_url: https://gateway.corp.example.com/v1
+  offline:
+    match:
+      paths: ["/srv/airgapped"]
+    ai:
+      provider: ollama
+`
+
+func newTestViper(t *testing.T) *viper.Viper {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(profilesYAML)); err != nil {
+		t.Fatal(err)
+	}
+	SetDefaults(v)
+	return v
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		explicit  string
+		repo      Repo
+		want      string
+		provider  string
+		openAIURL string
+	}{
+		{name: "no match keeps base", repo: Repo{Root: "/home/me/src/app", Remotes: []string{"https://github.com/other/app.git"}}, provider: "gemini"},
+		{name: "remote https", repo: Repo{Root: "/x", Remotes: []string{"https://github.com/acme/api.git"}}, want: "work", provider: "gpt", openAIURL: "https://gateway.corp.example.com/v1"},
+		{name: "remote scp", repo: Repo{Root: "/x", Remotes: []string{"git@git.corp.example.com:team/api.git"}}, want: "work", provider: "gpt", openAIURL: "https://gateway.corp.example.com/v1"},
+		{name: "path below root", repo: Repo{Root: "/srv/airgapped/tool"}, want: "offline", provider: "ollama"},
+		{name: "explicit wins over match", explicit: "offline", repo: Repo{Root: "/x", Remotes: []string{"https://github.com/acme/api.git"}}, want: "offline", provider: "ollama"},
+		{name: "outside a repository", provider: "gemini"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestViper(t)
+			if tt.explicit != "" {
+				v.Set("profile", tt.explicit)
+			}
+
+			got, err := ApplyProfile(v, tt.repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("profile = %q, want %q", got, tt.want)
+			}
+			if p := v.GetString("ai.provider"); p != tt.provider {
+				t.Errorf("ai.provider = %q, want %q", p, tt.provider)
+			}
+			if u := v.GetString("openai.base_url"); u != tt.openAIURL {
+				t.Errorf("openai.base_url = %q, want %q", u, tt.openAIURL)
+			}
+			// keys the profile does not set are inherited from the base config
+			if m := v.GetString("openai.model"); m != "gpt-4o" {
+				t.Errorf("openai.model = %q, want inherited gpt-4o", m)
+			}
+		})
+	}
+}
+
+func TestApplyProfile_Unknown(t *testing.T) {
+	v := newTestViper(t)
+	v.Set("profile", "missing")
+
+	_, err := ApplyProfile(v, Repo{})
+	if err == nil || !strings.Contains(err.Error(), "offline, work") {
+		t.Fatalf("expected unknown profile error listing known profiles, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package conflict
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	markerOurs   = "<<<<<<<"
+	markerBase   = "|||||||"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+var ErrUnterminatedConflict = errors.New("unterminated conflict marker")
+
+// Hunk is a single conflict region delimited by git's conflict markers.
+// Base is only populated when the merge used the diff3/zdiff3 conflict style.
+type Hunk struct {
+	Ours        string
+	Base        string
+	Theirs      string
+	OursLabel   string
+	TheirsLabel string
+	// StartLine is the 1-based line of the opening "<<<<<<<" marker.
+	StartLine int
+
+	raw        string
+	resolution *string
+}
+
+// Resolve sets the text that replaces the hunk (markers included) when the
+// file is rendered.
+func (h *Hunk) Resolve(text string) {
+	h.resolution = &text
+}
+
+// Reset drops a previously chosen resolution, keeping the conflict markers.
+func (h *Hunk) Reset() {
+	h.resolution = nil
+}
+
+// Resolved reports whether a resolution has been chosen for the hunk.
+func (h *Hunk) Resolved() bool {
+	return h.resolution != nil
+}
+
+// segment is either plain file content or a conflict hunk.
+type segment struct {
+	text string
+	hunk *Hunk
+}
+
+// File is a parsed file containing zero or more conflict hunks.
+type File struct {
+	Path     string
+	segments []segment
+}
+
+// Parse splits content into plain text and conflict hunks.
+func Parse(path, content string) (*File, error) {
+	f := &File{Path: path}
+	lines := strings.SplitAfter(content, "\n")
+
+	var plain strings.Builder
+	var cur *Hunk
+	var raw, section strings.Builder
+	// 0 = outside, 1 = ours, 2 = base, 3 = theirs
+	state := 0
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		bare := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case state == 0 && isMarker(bare, markerOurs):
+			if plain.Len() > 0 {
+				f.segments = append(f.segments, segment{text: plain.String()})
+				plain.Reset()
+			}
+			cur = &Hunk{OursLabel: markerLabel(bare, markerOurs), StartLine: i + 1}
+			raw.Reset()
+			section.Reset()
+			raw.WriteString(line)
+			state = 1
+		case state == 1 && isMarker(bare, markerBase):
+			cur.Ours = section.String()
+			section.Reset()
+			raw.WriteString(line)
+			state = 2
+		case (state == 1 || state == 2) && bare == markerSep:
+			if state == 1 {
+				cur.Ours = section.String()
+			} else {
+				cur.Base = section.String()
+			}
+			section.Reset()
+			raw.WriteString(line)
+			state = 3
+		case state == 3 && isMarker(bare, markerTheirs):
+			cur.Theirs = section.String()
+			cur.TheirsLabel = markerLabel(bare, markerTheirs)
+			raw.WriteString(line)
+			cur.raw = raw.String()
+			f.segments = append(f.segments, segment{hunk: cur})
+			cur = nil
+			state = 0
+		case state == 0:
+			plain.WriteString(line)
+		default:
+			section.WriteString(line)
+			raw.WriteString(line)
+		}
+	}
+
+	if state != 0 {
+		return nil, fmt.Errorf("%s:%d: %w", path, cur.StartLine, ErrUnterminatedConflict)
+	}
+	if plain.Len() > 0 {
+		f.segments = append(f.segments, segment{text: plain.String()})
+	}
+
+	return f, nil
+}
+
+func isMarker(line, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ")
+}
+
+func markerLabel(line, marker string) string {
+	return strings.TrimSpace(strings.TrimPrefix(line, marker))
+}
+
+// Hunks returns the conflict hunks in file order.
+func (f *File) Hunks() []*Hunk {
+	var hunks []*Hunk
+	for _, s := range f.segments {
+		if s.hunk != nil {
+			hunks = append(hunks, s.hunk)
+		}
+	}
+	return hunks
+}
+
+// Unresolved returns the number of hunks without a resolution.
+func (f *File) Unresolved() int {
+	n := 0
+	for _, h := range f.Hunks() {
+		if !h.Resolved() {
+			n++
+		}
+	}
+	return n
+}
+
+// Render rebuilds the file content, substituting resolved hunks and keeping
+// the original conflict markers for unresolved ones.
+func (f *File) Render() string {
+	var b strings.Builder
+	for _, s := range f.segments {
+		switch {
+		case s.hunk == nil:
+			b.WriteString(s.text)
+		case s.hunk.resolution != nil:
+			b.WriteString(*s.hunk.resolution)
+		default:
+			b.WriteString(s.hunk.raw)
+		}
+	}
+	return b.String()
+}
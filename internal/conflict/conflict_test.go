@@ -0,0 +1,117 @@
+package conflict
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Hunk
+	}{
+		{
+			name:  "no conflicts",
+			input: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name: "merge style",
+			input: "a\n" +
+				"<<<<<<< HEAD\nours\n=======\ntheirs\n>>>>>>> feature\n" +
+				"b\n",
+			want: []Hunk{{Ours: "ours\n", Theirs: "theirs\n", OursLabel: "HEAD", TheirsLabel: "feature", StartLine: 2}},
+		},
+		{
+			name: "diff3 base section",
+			input: "<<<<<<< HEAD\nours\n||||||| merged common ancestors\nbase\n=======\ntheirs\n>>>>>>> feature\n" +
+				"mid\n" +
+				"<<<<<<< HEAD\n=======\nadded\n>>>>>>> feature\n",
+			want: []Hunk{
+				{Ours: "ours\n", Base: "base\n", Theirs: "theirs\n", OursLabel: "HEAD", TheirsLabel: "feature", StartLine: 1},
+				{Ours: "", Theirs: "added\n", OursLabel: "HEAD", TheirsLabel: "feature", StartLine: 9},
+			},
+		},
+		{
+			name:  "CRLF",
+			input: "a\r\n<<<<<<< HEAD\r\nours\r\n=======\r\ntheirs\r\n>>>>>>> feature\r\nb\r\n",
+			want:  []Hunk{{Ours: "ours\r\n", Theirs: "theirs\r\n", OursLabel: "HEAD", TheirsLabel: "feature", StartLine: 2}},
+		},
+		{
+			name:  "marker-like text is content",
+			input: "<<<<<<<<< not a marker\n<<<<<<< HEAD\n======== long rule\n=======\n>>>>>>> feature\n",
+			want:  []Hunk{{Ours: "======== long rule\n", Theirs: "", OursLabel: "HEAD", TheirsLabel: "feature", StartLine: 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse("file.go", tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hunks := f.Hunks()
+			if len(hunks) != len(tt.want) {
+				t.Fatalf("got %d hunks, want %d", len(hunks), len(tt.want))
+			}
+			for i, h := range hunks {
+				w := tt.want[i]
+				if h.Ours != w.Ours || h.Base != w.Base || h.Theirs != w.Theirs ||
+					h.OursLabel != w.OursLabel || h.TheirsLabel != w.TheirsLabel || h.StartLine != w.StartLine {
+					t.Errorf("hunk %d = %+v, want %+v", i, *h, w)
+				}
+			}
+			// untouched hunks render back to the input byte for byte
+			if got := f.Render(); got != tt.input {
+				t.Errorf("Render() =\n%q\nwant\n%q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestParse_Unterminated(t *testing.T) {
+	for _, input := range []string{
+		"a\n<<<<<<< HEAD\nours\n",
+		"<<<<<<< HEAD\nours\n=======\ntheirs\n",
+		"<<<<<<< HEAD\nours\n||||||| base\nbase\n",
+	} {
+		_, err := Parse("file.go", input)
+		if !errors.Is(err, ErrUnterminatedConflict) {
+			t.Errorf("Parse(%q) error = %v, want ErrUnterminatedConflict", input, err)
+		}
+	}
+
+	_, err := Parse("file.go", "x\n<<<<<<< HEAD\nours\n")
+	if err == nil || !strings.HasPrefix(err.Error(), "file.go:2:") {
+		t.Errorf("error %v should name the file and the line of the marker", err)
+	}
+}
+
+func TestRender_Resolutions(t *testing.T) {
+	input := "a\n" +
+		"<<<<<<< HEAD\none\n=======\nuno\n>>>>>>> es\n" +
+		"b\r\n" +
+		"<<<<<<< HEAD\ntwo\n=======\ndos\n>>>>>>> es\n"
+	f, err := Parse("file.txt", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hunks := f.Hunks()
+	if f.Unresolved() != 2 {
+		t.Fatalf("Unresolved() = %d, want 2", f.Unresolved())
+	}
+
+	hunks[1].Resolve("two\ndos\n")
+	want := "a\n<<<<<<< HEAD\none\n=======\nuno\n>>>>>>> es\nb\r\ntwo\ndos\n"
+	if got := f.Render(); got != want {
+		t.Errorf("Render() with one resolution =\n%q\nwant\n%q", got, want)
+	}
+	if f.Unresolved() != 1 || hunks[0].Resolved() || !hunks[1].Resolved() {
+		t.Errorf("resolution state wrong: unresolved %d", f.Unresolved())
+	}
+
+	hunks[1].Reset()
+	if got := f.Render(); got != input {
+		t.Errorf("Render() after Reset =\n%q\nwant the input", got)
+	}
+}
@@ -0,0 +1,119 @@
+// Package credentials resolves provider API keys from commands, the OS
+// keyring, or a private file.
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Store selects where keys saved with `gitai config set-key` live.
+type Store string
+
+const (
+	// StoreNone disables stored keys; only config and environment are used.
+	StoreNone Store = ""
+	// StoreKeyring uses the OS keyring and falls back to StoreFile when no
+	// keyring is available, e.g. on headless Linux.
+	StoreKeyring Store = "keyring"
+	// StoreFile keeps keys in a file readable only by the user.
+	StoreFile Store = "file"
+)
+
+// ParseStore parses ai.credential_store.
+func ParseStore(s string) (Store, error) {
+	switch st := Store(strings.ToLower(strings.TrimSpace(s))); st {
+	case StoreNone, StoreKeyring, StoreFile:
+		return st, nil
+	case "none":
+		return StoreNone, nil
+	default:
+		return StoreNone, fmt.Errorf("unknown credential store %q (expected keyring|file)", s)
+	}
+}
+
+// ErrNotFound is returned by Lookup when no key is stored for the provider.
+var ErrNotFound = errors.New("no stored API key")
+
+// errKeyringUnavailable means the platform has no usable keyring tool.
+var errKeyringUnavailable = errors.New("OS keyring not available")
+
+// keyringGet and keyringSet are variables so tests can stand in for the OS
+// keyring.
+var (
+	keyringGet = systemKeyringGet
+	keyringSet = systemKeyringSet
+)
+
+// Lookup returns the key stored for provider. With StoreKeyring the file
+// store is also read when the keyring has no key: Save falls back to the
+// file when the keyring cannot store it, and a keyring that cannot store a
+// key usually cannot find one either.
+func Lookup(store Store, provider string) (string, error) {
+	switch store {
+	case StoreKeyring:
+		key, err := keyringGet(provider)
+		if errors.Is(err, errKeyringUnavailable) || errors.Is(err, ErrNotFound) {
+			return fileGet(provider)
+		}
+		return key, err
+	case StoreFile:
+		return fileGet(provider)
+	default:
+		return "", ErrNotFound
+	}
+}
+
+// Save stores key for provider and returns a description of where it went.
+func Save(store Store, provider, key string) (string, error) {
+	switch store {
+	case StoreKeyring:
+		err := keyringSet(provider, key)
+		if errors.Is(err, errKeyringUnavailable) {
+			return fileSet(provider, key)
+		}
+		return "OS keyring", err
+	case StoreFile:
+		return fileSet(provider, key)
+	default:
+		return "", errors.New("no credential store configured")
+	}
+}
+
+// commandTimeout bounds api_key_command so a hung helper cannot block gitai.
+const commandTimeout = 30 * time.Second
+
+// RunCommand runs an api_key_command such as `pass show openai` through the
+// shell and returns the first line of its output.
+func RunCommand(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("api_key_command %q failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// helpers like pass print metadata after the secret on later lines
+	key, _, _ := strings.Cut(string(out), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("api_key_command %q printed nothing", command)
+	}
+	return key, nil
+}
@@ -0,0 +1,159 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeKeyring replaces the OS keyring for one test.
+func fakeKeyring(t *testing.T, get func(string) (string, error), set func(string, string) error) {
+	t.Helper()
+	oldGet, oldSet := keyringGet, keyringSet
+	keyringGet, keyringSet = get, set
+	t.Cleanup(func() { keyringGet, keyringSet = oldGet, oldSet })
+}
+
+func TestFileStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if _, err := Lookup(StoreFile, "openai"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Lookup() before Save = %v, want ErrNotFound", err)
+	}
+
+	where, err := Save(StoreFile, "openai", "sk-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Save(StoreFile, "gemini", "g-two"); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".config", "gitai", "credentials.yaml"); where != want {
+		t.Errorf("Save() stored in %s, want %s", where, want)
+	}
+
+	for provider, want := range map[string]string{"openai": "sk-one", "gemini": "g-two"} {
+		if got, err := Lookup(StoreFile, provider); err != nil || got != want {
+			t.Errorf("Lookup(%s) = %q, %v; want %q", provider, got, err, want)
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, err := os.Stat(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("credentials file mode = %o, want 600", perm)
+	}
+	if info, err := os.Stat(filepath.Dir(where)); err != nil || info.Mode().Perm() != 0o700 {
+		t.Errorf("credentials directory mode = %v, %v; want 700", info.Mode().Perm(), err)
+	}
+
+	if err := os.Chmod(where, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lookup(StoreFile, "openai"); err == nil {
+		t.Error("Lookup() read a credentials file other users can read")
+	}
+}
+
+func TestKeyringFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		getErr  error
+		setErr  error
+		wantKey string
+	}{
+		// e.g. secret-tool without a D-Bus session
+		{"keyring unavailable", errKeyringUnavailable, errKeyringUnavailable, "sk-file"},
+		// the keyring tool runs but cannot see the key saved to the file
+		{"keyring has no key", ErrNotFound, errKeyringUnavailable, "sk-file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			fakeKeyring(t,
+				func(string) (string, error) { return "", tt.getErr },
+				func(string, string) error { return tt.setErr },
+			)
+
+			where, err := Save(StoreKeyring, "openai", "sk-file")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if where == "OS keyring" {
+				t.Fatalf("Save() used the keyring, want the file store")
+			}
+			if got, err := Lookup(StoreKeyring, "openai"); err != nil || got != tt.wantKey {
+				t.Errorf("Lookup() = %q, %v; want %q", got, err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestKeyringPreferred(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stored := map[string]string{}
+	fakeKeyring(t,
+		func(p string) (string, error) {
+			if k, ok := stored[p]; ok {
+				return k, nil
+			}
+			return "", ErrNotFound
+		},
+		func(p, k string) error { stored[p] = k; return nil },
+	)
+
+	where, err := Save(StoreKeyring, "openai", "sk-ring")
+	if err != nil || where != "OS keyring" {
+		t.Fatalf("Save() = %q, %v; want the OS keyring", where, err)
+	}
+	if got, err := Lookup(StoreKeyring, "openai"); err != nil || got != "sk-ring" {
+		t.Errorf("Lookup() = %q, %v; want sk-ring", got, err)
+	}
+	if _, err := Lookup(StoreKeyring, "gemini"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Lookup() of a missing key = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSecurityCommand(t *testing.T) {
+	got := securityCommand("add-generic-password", "-w", `s"k\1`)
+	want := `"add-generic-password" "-w" "s\"k\\1"` + "\n"
+	if got != want {
+		t.Errorf("securityCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestParseStore(t *testing.T) {
+	for in, want := range map[string]Store{"": StoreNone, "none": StoreNone, " Keyring ": StoreKeyring, "file": StoreFile} {
+		if got, err := ParseStore(in); err != nil || got != want {
+			t.Errorf("ParseStore(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseStore("vault"); err == nil {
+		t.Error("ParseStore(vault) succeeded")
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	key, err := RunCommand(context.Background(), `printf 'sk-123\nuser: me\n'`)
+	if err != nil || key != "sk-123" {
+		t.Errorf("RunCommand() = %q, %v; want sk-123", key, err)
+	}
+	if _, err := RunCommand(context.Background(), "true"); err == nil {
+		t.Error("RunCommand() accepted empty output")
+	}
+	if _, err := RunCommand(context.Background(), "exit 3"); err == nil {
+		t.Error("RunCommand() accepted a failing command")
+	}
+}
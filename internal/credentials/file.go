@@ -0,0 +1,76 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// filePath is the fallback store next to the user config file: a YAML map of
+// provider to key that only the user can read.
+func filePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gitai", "credentials.yaml"), nil
+}
+
+func readFile() (map[string]string, string, error) {
+	path, err := filePath()
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, path, nil
+	}
+	if err != nil {
+		return nil, path, err
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+		return nil, path, fmt.Errorf("%s is readable by other users; run chmod 600 %s", path, path)
+	}
+
+	keys := map[string]string{}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return nil, path, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return keys, path, nil
+}
+
+func fileGet(provider string) (string, error) {
+	keys, _, err := readFile()
+	if err != nil {
+		return "", err
+	}
+	if key := keys[provider]; key != "" {
+		return key, nil
+	}
+	return "", ErrNotFound
+}
+
+func fileSet(provider, key string) (string, error) {
+	keys, path, err := readFile()
+	if err != nil {
+		return "", err
+	}
+	keys[provider] = key
+
+	data, err := yaml.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,93 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// service is the keyring service name; the provider is the account.
+const service = "gitai"
+
+// systemKeyringGet reads from the macOS keychain (security) or the Secret Service
+// (secret-tool). Other platforms report errKeyringUnavailable.
+func systemKeyringGet(provider string) (string, error) {
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", provider, "-w")
+	case hasSecretTool():
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", provider)
+	default:
+		return "", errKeyringUnavailable
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// secret-tool exits 1 without a message when the item does not
+		// exist and complains on stderr when the Secret Service is missing,
+		// e.g. without a D-Bus session
+		if runtime.GOOS != "darwin" && strings.TrimSpace(stderr.String()) != "" {
+			return "", errKeyringUnavailable
+		}
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", errKeyringUnavailable
+	}
+
+	key := strings.TrimSpace(string(out))
+	if key == "" {
+		return "", ErrNotFound
+	}
+	return key, nil
+}
+
+func systemKeyringSet(provider, key string) error {
+	// both tools read the secret from stdin so it never shows up in ps
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(securityCommand("add-generic-password", "-U", "-s", service, "-a", provider, "-w", key))
+	case hasSecretTool():
+		cmd = exec.Command("secret-tool", "store", "--label", "gitai "+provider+" API key", "service", service, "account", provider)
+		cmd.Stdin = strings.NewReader(key)
+	default:
+		return errKeyringUnavailable
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if runtime.GOOS != "darwin" {
+			// e.g. no D-Bus session on a headless machine
+			return errKeyringUnavailable
+		}
+		return errors.New(strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func hasSecretTool() bool {
+	if runtime.GOOS != "linux" && runtime.GOOS != "freebsd" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+// securityCommand renders a command line for `security -i`, which splits
+// its input like a shell: arguments are double-quoted with backslash escapes.
+func securityCommand(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		a = strings.ReplaceAll(a, `\`, `\\`)
+		a = strings.ReplaceAll(a, `"`, `\"`)
+		quoted[i] = `"` + a + `"`
+	}
+	return strings.Join(quoted, " ") + "\n"
+}
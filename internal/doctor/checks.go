@@ -0,0 +1,316 @@
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/git"
+)
+
+// minGitVersion is the oldest git supporting every command gitai runs
+// (rev-parse --path-format).
+var minGitVersion = [2]int{2, 31}
+
+// Provider endpoints probed when the config does not override them.
+var (
+	openAIEndpoint = "https://api.openai.com/v1"
+	geminiEndpoint = "https://generativelanguage.googleapis.com/v1beta"
+)
+
+func checkGit(_ context.Context, _ Options) Result {
+	r := Result{Check: "git"}
+
+	version, err := git.Version()
+	if err != nil {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("git not found: %v", err)
+		return r
+	}
+
+	r.Status, r.Detail = StatusPass, "git "+version
+	if major, minor, ok := parseVersion(version); ok && (major < minGitVersion[0] || major == minGitVersion[0] && minor < minGitVersion[1]) {
+		r.Status = StatusWarn
+		r.Detail += fmt.Sprintf(" is older than %d.%d; some commands may fail", minGitVersion[0], minGitVersion[1])
+	}
+	return r
+}
+
+func parseVersion(v string) (major, minor int, ok bool) {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	return major, minor, err1 == nil && err2 == nil
+}
+
+func checkRepo(_ context.Context, _ Options) Result {
+	r := Result{Check: "repository"}
+
+	root, err := git.GetGitRoot()
+	if err != nil {
+		r.Status, r.Detail = StatusWarn, "not inside a git repository"
+		return r
+	}
+
+	conflicted, err := git.GetConflictedFiles()
+	if err != nil {
+		r.Status, r.Detail = StatusFail, err.Error()
+		return r
+	}
+	if len(conflicted) > 0 {
+		r.Status, r.Detail = StatusWarn, fmt.Sprintf("%s has %d conflicted file(s); run gitai resolve", root, len(conflicted))
+		return r
+	}
+
+	r.Status, r.Detail = StatusPass, root
+	return r
+}
+
+func checkConfig(_ context.Context, opts Options) Result {
+	r := Result{Check: "config"}
+
+	file := opts.ConfigFile
+	if file == "" {
+		file = "no config file found, using defaults and environment"
+	}
+
+	switch {
+	case opts.ConfigErr != nil:
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("%s: %v", file, opts.ConfigErr)
+	case len(opts.UnknownKeys) > 0:
+		r.Status, r.Detail = StatusWarn, fmt.Sprintf("%s: unknown keys %s", file, strings.Join(opts.UnknownKeys, ", "))
+	default:
+		r.Status, r.Detail = StatusPass, file
+		if opts.Config.Profile != "" {
+			r.Detail += " (profile " + opts.Config.Profile + ")"
+		}
+	}
+	return r
+}
+
+func checkProvider(_ context.Context, opts Options) Result {
+	r := Result{Check: "provider"}
+
+	switch {
+	case opts.Config == nil:
+		r.Status, r.Detail = StatusWarn, "skipped: config is invalid"
+	case opts.Config.Provider() == ai.ProviderNone:
+		r.Status, r.Detail = StatusFail, "ai.provider is not set (gpt|gemini|ollama|geminicli)"
+	default:
+		r.Status, r.Detail = StatusPass, string(opts.Config.Provider())
+	}
+	return r
+}
+
+func checkAPIKey(_ context.Context, opts Options) Result {
+	r := Result{Check: "api key"}
+
+	if opts.Config == nil || opts.Config.Provider() == ai.ProviderNone {
+		r.Status, r.Detail = StatusWarn, "skipped: no provider"
+		return r
+	}
+
+	cfg := opts.Config.AIConfig()
+	var key string
+	switch cfg.Provider {
+	case ai.ProviderGPT:
+		key = cfg.OpenAI.APIKey
+	case ai.ProviderGemini:
+		key = cfg.Gemini.APIKey
+	default:
+		r.Status, r.Detail = StatusPass, "not needed for "+string(cfg.Provider)
+		return r
+	}
+
+	if key == "" {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("no key found; set %s.api_key, an api_key_command, or run gitai config set-key %s", section(cfg.Provider), cfg.Provider)
+		return r
+	}
+	r.Status, r.Detail = StatusPass, "key found"
+	return r
+}
+
+func section(p ai.Provider) string {
+	if p == ai.ProviderGemini {
+		return "gemini"
+	}
+	return "openai"
+}
+
+func checkEndpoint(ctx context.Context, opts Options) Result {
+	r := Result{Check: "endpoint"}
+
+	if opts.Config == nil || opts.Config.Provider() == ai.ProviderNone {
+		r.Status, r.Detail = StatusWarn, "skipped: no provider"
+		return r
+	}
+
+	cfg := opts.Config.AIConfig()
+	switch cfg.Provider {
+	case ai.ProviderGPT:
+		base := cfg.OpenAI.BaseURL
+		if base == "" {
+			base = openAIEndpoint
+		}
+		return probe(ctx, opts, strings.TrimSuffix(base, "/")+"/models", "Authorization", "Bearer "+cfg.OpenAI.APIKey, cfg.OpenAI.APIKey != "")
+	case ai.ProviderGemini:
+		return probe(ctx, opts, geminiEndpoint+"/models", "x-goog-api-key", cfg.Gemini.APIKey, cfg.Gemini.APIKey != "")
+	case ai.ProviderOllama:
+		return checkOllama(ctx, opts, cfg.Ollama)
+	case ai.ProvideGeminiCLI:
+		path, err := exec.LookPath("gemini")
+		if err != nil {
+			r.Status, r.Detail = StatusFail, "gemini CLI not found in PATH"
+			return r
+		}
+		r.Status, r.Detail = StatusPass, path
+		return r
+	}
+	return r
+}
+
+// probe treats any HTTP response as reachable; with a key, 401/403 means the
+// key was rejected.
+func probe(ctx context.Context, opts Options, url, header, value string, withKey bool) Result {
+	r := Result{Check: "endpoint"}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		r.Status, r.Detail = StatusFail, err.Error()
+		return r
+	}
+	if withKey {
+		req.Header.Set(header, value)
+	}
+
+	resp, err := opts.HTTPClient.Do(req)
+	if err != nil {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("%s unreachable: %v", url, err)
+		return r
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300 && withKey:
+		r.Status, r.Detail = StatusPass, fmt.Sprintf("%s reachable, key accepted", url)
+	case (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && withKey:
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("%s rejected the key (%s)", url, resp.Status)
+	case resp.StatusCode >= 500:
+		r.Status, r.Detail = StatusWarn, fmt.Sprintf("%s reachable but returned %s", url, resp.Status)
+	default:
+		r.Status, r.Detail = StatusPass, fmt.Sprintf("%s reachable (%s)", url, resp.Status)
+	}
+	return r
+}
+
+func checkOllama(ctx context.Context, opts Options, cfg ai.OllamaConfig) Result {
+	r := Result{Check: "endpoint"}
+
+	if cfg.Path == "" {
+		r.Status, r.Detail = StatusFail, "ollama.path is not set (or set OLLAMA_API_PATH)"
+		return r
+	}
+	model := cfg.Model
+	if model == "" {
+		model = ai.DefaultOllamaModel
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, cfg.Path, "list").CombinedOutput()
+	if err != nil {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("%s list failed: %v %s", cfg.Path, err, strings.TrimSpace(string(out)))
+		return r
+	}
+
+	if !hasOllamaModel(out, model) {
+		r.Status, r.Detail = StatusFail, fmt.Sprintf("model %s is not pulled; run ollama pull %s", model, model)
+		return r
+	}
+	r.Status, r.Detail = StatusPass, "ollama model "+model+" available"
+	return r
+}
+
+// hasOllamaModel looks for model in the NAME column of `ollama list`; a name
+// without a tag matches ":latest".
+func hasOllamaModel(listOutput []byte, model string) bool {
+	if !strings.Contains(model, ":") {
+		model += ":latest"
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(listOutput))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) > 0 && fields[0] == model {
+			return true
+		}
+	}
+	return false
+}
+
+func checkHooks(_ context.Context, _ Options) Result {
+	r := Result{Check: "hooks"}
+
+	dir, err := git.GetHooksDir()
+	if err != nil {
+		r.Status, r.Detail = StatusWarn, "skipped: not inside a git repository"
+		return r
+	}
+
+	var installed []string
+	for _, hook := range []string{"pre-commit", "commit-msg"} {
+		data, err := os.ReadFile(filepath.Join(dir, hook))
+		if err == nil && bytes.Contains(data, []byte("gitai")) {
+			installed = append(installed, hook)
+		}
+	}
+
+	if len(installed) == 0 {
+		r.Status, r.Detail = StatusWarn, "no hook in "+dir+" runs gitai (see the README for a pre-commit example)"
+		return r
+	}
+	r.Status, r.Detail = StatusPass, strings.Join(installed, ", ")+" installed"
+	return r
+}
+
+func checkTerminal(_ context.Context, _ Options) Result {
+	r := Result{Check: "terminal"}
+
+	fd := os.Stdout.Fd()
+	if !term.IsTerminal(fd) {
+		r.Status, r.Detail = StatusWarn, "stdout is not a terminal; interactive commands need one"
+		return r
+	}
+
+	termName := os.Getenv("TERM")
+	if termName == "dumb" {
+		r.Status, r.Detail = StatusWarn, "TERM=dumb; the TUI will not render correctly"
+		return r
+	}
+
+	r.Status, r.Detail = StatusPass, "TERM="+termName
+	if w, h, err := term.GetSize(fd); err == nil {
+		r.Detail += fmt.Sprintf(", %dx%d", w, h)
+		if w < 80 {
+			r.Status = StatusWarn
+			r.Detail += " (narrower than 80 columns)"
+		}
+	}
+	return r
+}
@@ -0,0 +1,108 @@
+// Package doctor diagnoses the environment gitai runs in: git, the
+// repository, configuration, the selected provider and the terminal.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"huseynovvusal/gitai/internal/config"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Result is the outcome of one check.
+type Result struct {
+	Check  string `json:"check"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Options is what the checks inspect. The config is loaded by the caller so
+// that a broken config is reported as a failed check instead of aborting.
+type Options struct {
+	// Config is nil when ConfigErr is set.
+	Config      *config.Config
+	ConfigErr   error
+	ConfigFile  string
+	UnknownKeys []string
+	// HTTPClient probes provider endpoints; nil uses a client with Timeout.
+	HTTPClient *http.Client
+	Timeout    time.Duration
+}
+
+// DefaultTimeout bounds every network probe and external command.
+const DefaultTimeout = 5 * time.Second
+
+// Run executes every check in order.
+func Run(ctx context.Context, opts Options) []Result {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: opts.Timeout}
+	}
+
+	checks := []func(context.Context, Options) Result{
+		checkGit,
+		checkRepo,
+		checkConfig,
+		checkProvider,
+		checkAPIKey,
+		checkEndpoint,
+		checkHooks,
+		checkTerminal,
+	}
+
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, check(ctx, opts))
+	}
+	return results
+}
+
+// Failed reports whether any check failed.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Output formats accepted by WriteReport.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// WriteReport writes results as an aligned table or as a JSON array.
+func WriteReport(w io.Writer, format string, results []Result) error {
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "STATUS\tCHECK\tDETAIL")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Status, r.Check, r.Detail)
+		}
+		return tw.Flush()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	default:
+		return fmt.Errorf("unknown format %q (expected text|json)", format)
+	}
+}
@@ -0,0 +1,88 @@
+package doctor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/config"
+)
+
+func TestCheckEndpoint_OpenAICompatible(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Header.Get("Authorization") {
+		case "Bearer good":
+			w.WriteHeader(http.StatusOK)
+		case "Bearer down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		key  string
+		want Status
+	}{
+		{key: "good", want: StatusPass},
+		{key: "bad", want: StatusFail},
+		{key: "down", want: StatusWarn},
+		// without a key, any response proves the endpoint is reachable
+		{key: "", want: StatusPass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			cfg := &config.Config{AI: config.AIConfig{Provider: "gpt"}, OpenAI: ai.OpenAIConfig{BaseURL: srv.URL + "/v1/"}}
+			if err := cfg.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			cfg.SetAPIKey(tt.key)
+
+			got := checkEndpoint(context.Background(), Options{Config: cfg, HTTPClient: srv.Client(), Timeout: DefaultTimeout})
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s (%s)", got.Status, tt.want, got.Detail)
+			}
+		})
+	}
+}
+
+func TestCheckEndpoint_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	cfg := &config.Config{AI: config.AIConfig{Provider: "gpt"}, OpenAI: ai.OpenAIConfig{BaseURL: url}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := checkEndpoint(context.Background(), Options{Config: cfg, HTTPClient: http.DefaultClient, Timeout: DefaultTimeout})
+	if got.Status != StatusFail {
+		t.Errorf("status = %s, want fail (%s)", got.Status, got.Detail)
+	}
+}
+
+func TestHasOllamaModel(t *testing.T) {
+	list := []byte("NAME               ID              SIZE      MODIFIED\n" +
+		"llama3.1:8b        46e0c10c039e    4.9 GB    3 weeks ago\n" +
+		"mistral:latest     f974a74358d6    4.1 GB    2 months ago\n")
+
+	for model, want := range map[string]bool{
+		"llama3.1:8b":  true,
+		"mistral":      true,
+		"llama3.1:70b": false,
+		"llama3.1":     false,
+	} {
+		if got := hasOllamaModel(list, model); got != want {
+			t.Errorf("hasOllamaModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
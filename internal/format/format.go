@@ -0,0 +1,193 @@
+// Package format defines commit message format presets: the prompt
+// fragment that asks for the format, the lint rules that check it and
+// examples of it. Presets are built in or defined in the config.
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"huseynovvusal/gitai/internal/lint"
+)
+
+// Preset is one commit message format.
+type Preset struct {
+	Name string `mapstructure:"name"`
+	// Prompt describes the format to the model. It is empty for
+	// conventional, which the built-in system prompt already asks for.
+	Prompt string `mapstructure:"prompt"`
+	// Examples are sample messages shown with the prompt.
+	Examples []string `mapstructure:"examples"`
+	// Pattern is a regular expression the header must match.
+	Pattern string `mapstructure:"pattern"`
+	// Conventional checks the header as type(scope): subject, restricted to
+	// Types when set.
+	Conventional bool     `mapstructure:"conventional"`
+	Types        []string `mapstructure:"types"`
+}
+
+// Config is the `format` section of the gitai config.
+type Config struct {
+	// Preset names the format in use, built in or from Presets.
+	Preset string `mapstructure:"preset"`
+	// Presets add formats or replace built-in ones of the same name.
+	Presets []Preset `mapstructure:"presets"`
+}
+
+// Names of the built-in presets.
+const (
+	Conventional = "conventional"
+	Angular      = "angular"
+	Gitmoji      = "gitmoji"
+	Kernel       = "kernel"
+	Plain        = "plain"
+)
+
+// DefaultPreset is used when format.preset is empty.
+const DefaultPreset = Conventional
+
+var builtIn = []Preset{
+	{
+		Name:         Conventional,
+		Conventional: true,
+		Examples: []string{
+			"feat(auth): add login with OAuth",
+			"fix(api)!: reject empty payloads\n\nBREAKING CHANGE: POST /items with an empty body now returns 400.",
+		},
+	},
+	{
+		Name: Angular,
+		Prompt: `Use the Angular commit format: "<type>(<scope>): <subject>".
+- type is one of build, ci, docs, feat, fix, perf, refactor, test
+- the subject is imperative, starts with a lowercase letter and has no trailing period
+- the body explains the motivation; footers hold "BREAKING CHANGE: ..." and "Closes #123"`,
+		Examples: []string{
+			"fix(router): resolve lazy routes relative to the parent\n\nCloses #4521",
+			"perf(compiler): cache parsed templates",
+		},
+		Pattern:      `^[a-z]+(\([^()]+\))?: [a-z].*[^.]$`,
+		Conventional: true,
+		Types:        []string{"build", "ci", "docs", "feat", "fix", "perf", "refactor", "test"},
+	},
+	{
+		Name: Gitmoji,
+		Prompt: `Use the gitmoji format: start the subject with the one emoji that fits the change best, followed by an imperative summary and no type prefix.
+- ✨ new feature, 🐛 bug fix, 📝 documentation, ♻️ refactor, ⚡️ performance, ✅ tests
+- 🔧 configuration, ⬆️ dependency upgrade, 🔥 removal, 🚑️ hotfix, 💄 UI and styles`,
+		Examples: []string{
+			"✨ Add dark mode toggle to settings",
+			"🐛 Fix crash when the cache directory is missing",
+		},
+		Pattern: `^(:[a-z0-9_+-]+:|[\x{2190}-\x{2BFF}\x{1F000}-\x{1FAFF}]\x{FE0F}?) +\S`,
+	},
+	{
+		Name: Kernel,
+		Prompt: `Use the Linux kernel format: "subsystem: summary".
+- subsystem is the component changed, e.g. "net: ipv4" or "docs"; nest with further colons
+- the summary is imperative, without a trailing period
+- the body explains what was wrong and why this fixes it, in plain prose without lists`,
+		Examples: []string{
+			"mm: fix use-after-free in page cache eviction\n\nThe folio was released before its mapping was checked, so a\nconcurrent truncate could free it first. Take the reference\nbefore dropping the lock.",
+			"docs: process: clarify the Fixes tag format",
+		},
+		Pattern: `^[A-Za-z0-9_./-]+(: [A-Za-z0-9_./-]+)*: \S.*[^.]$`,
+	},
+	{
+		Name: Plain,
+		Prompt: `Use a plain imperative subject without a type or scope prefix, starting with a capital letter and without a trailing period, e.g. "Add retry to the HTTP client".
+Add a body only when the reason for the change is not obvious from the subject.`,
+		Examples: []string{
+			"Add retry to the HTTP client",
+			"Handle empty config files\n\nAn empty file used to fail the YAML decoder; it now means defaults.",
+		},
+		Pattern: `^[A-Z].*[^.]$`,
+	},
+}
+
+// BuiltIn returns the names of the built-in presets.
+func BuiltIn() []string {
+	names := make([]string, len(builtIn))
+	for i, p := range builtIn {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Resolve returns the selected preset; presets from the config win over
+// built-in ones of the same name.
+func (c Config) Resolve() (Preset, error) {
+	name := strings.TrimSpace(c.Preset)
+	if name == "" {
+		name = DefaultPreset
+	}
+	for _, p := range c.Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	for _, p := range builtIn {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+
+	names := BuiltIn()
+	for _, p := range c.Presets {
+		names = append(names, p.Name)
+	}
+	return Preset{}, fmt.Errorf("unknown preset %q (expected %s)", name, strings.Join(names, "|"))
+}
+
+// All returns the built-in presets, replaced by configured ones of the same
+// name, followed by the other configured presets.
+func (c Config) All() []Preset {
+	var all []Preset
+	for _, b := range builtIn {
+		p := b
+		for _, custom := range c.Presets {
+			if strings.EqualFold(custom.Name, b.Name) {
+				p = custom
+				break
+			}
+		}
+		all = append(all, p)
+	}
+	for _, custom := range c.Presets {
+		if !slices.ContainsFunc(builtIn, func(b Preset) bool { return strings.EqualFold(b.Name, custom.Name) }) {
+			all = append(all, custom)
+		}
+	}
+	return all
+}
+
+func (c Config) Validate() error {
+	for i, p := range c.Presets {
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Errorf("presets[%d]: needs a name", i)
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("presets[%d] (%s): pattern: %w", i, p.Name, err)
+		}
+	}
+	_, err := c.Resolve()
+	return err
+}
+
+// Lint returns base with the preset's header rules. The conventional
+// preset leaves lint.conventional as configured, and the preset's pattern
+// is checked in addition to lint.header_pattern.
+func (p Preset) Lint(base lint.Config) lint.Config {
+	if p.Name != Conventional {
+		base.Conventional = p.Conventional
+	}
+	if len(p.Types) > 0 {
+		base.Types = p.Types
+	}
+	if p.Pattern != "" {
+		base.FormatPattern = p.Pattern
+		base.Format = p.Name
+	}
+	return base
+}
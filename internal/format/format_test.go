@@ -0,0 +1,122 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"huseynovvusal/gitai/internal/lint"
+)
+
+func TestResolve(t *testing.T) {
+	team := Preset{Name: "team", Pattern: `^\[[A-Z]+-\d+\] `}
+	plain := Preset{Name: "Plain", Pattern: `^.+$`}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		wantErr bool
+	}{
+		{"default", Config{}, Conventional, false},
+		{"built in", Config{Preset: "gitmoji"}, Gitmoji, false},
+		{"case insensitive", Config{Preset: "Kernel"}, Kernel, false},
+		{"custom", Config{Preset: "team", Presets: []Preset{team}}, "team", false},
+		{"custom replaces built in", Config{Preset: "plain", Presets: []Preset{plain}}, "Plain", false},
+		{"unknown", Config{Preset: "emoji"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.Resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+
+	if p, _ := (Config{Preset: "plain", Presets: []Preset{plain}}).Resolve(); p.Pattern != plain.Pattern {
+		t.Errorf("custom plain preset not used: %+v", p)
+	}
+}
+
+func TestAll(t *testing.T) {
+	cfg := Config{Presets: []Preset{{Name: "team"}, {Name: "kernel", Prompt: "ours"}}}
+	var names []string
+	for _, p := range cfg.All() {
+		names = append(names, p.Name)
+		if p.Name == Kernel && p.Prompt != "ours" {
+			t.Error("configured kernel preset did not replace the built-in one")
+		}
+	}
+	if got, want := strings.Join(names, ","), "conventional,angular,gitmoji,kernel,plain,team"; got != want {
+		t.Errorf("All() = %s, want %s", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{Preset: "team", Presets: []Preset{{Name: "team", Pattern: `^\w+`}}}).Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+	for _, cfg := range []Config{
+		{Presets: []Preset{{Pattern: `^\w+`}}},
+		{Presets: []Preset{{Name: "team", Pattern: "("}}},
+		{Preset: "team"},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", cfg)
+		}
+	}
+}
+
+// TestBuiltInPatterns checks every built-in preset against its own examples
+// and against headers of other formats.
+func TestBuiltInPatterns(t *testing.T) {
+	rejects := map[string][]string{
+		Angular: {"Feat(api): add x", "feat: add x.", "feat: Add x"},
+		Gitmoji: {"feat: add x", "Add x", "✨"},
+		Kernel:  {"Add x", "net: fix leak."},
+		Plain:   {"add x", "Add x.", "feat: add x"},
+	}
+	for _, p := range builtIn {
+		t.Run(p.Name, func(t *testing.T) {
+			if p.Pattern == "" {
+				return
+			}
+			re := regexp.MustCompile(p.Pattern)
+			for _, ex := range p.Examples {
+				header, _, _ := strings.Cut(ex, "\n")
+				if !re.MatchString(header) {
+					t.Errorf("example %q does not match %s", header, p.Pattern)
+				}
+			}
+			for _, header := range rejects[p.Name] {
+				if re.MatchString(header) {
+					t.Errorf("%q matches %s", header, p.Pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestPresetLint(t *testing.T) {
+	base := lint.Config{Conventional: true, Types: []string{"feat", "fix"}, HeaderPattern: `ABC-\d+`}
+
+	got := Preset{Name: Conventional, Conventional: true}.Lint(base)
+	if !got.Conventional || len(got.Types) != 2 || got.FormatPattern != "" {
+		t.Errorf("conventional changed the lint config: %+v", got)
+	}
+
+	// the preset's pattern is checked next to the user's, not instead of it
+	got = builtIn[2].Lint(base) // gitmoji
+	if got.Conventional || got.FormatPattern == "" || got.Format != Gitmoji || got.HeaderPattern != base.HeaderPattern {
+		t.Errorf("gitmoji lint config = %+v", got)
+	}
+
+	got = builtIn[1].Lint(base) // angular
+	if !got.Conventional || len(got.Types) != 8 {
+		t.Errorf("angular lint config = %+v", got)
+	}
+}
@@ -1,9 +1,11 @@
 package git
 
 import (
+	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 )
@@ -17,6 +19,117 @@ func GetDiff() (string, error) {
 	return string(out), err
 }
 
+// GetStagedDiff returns the diff of the index against HEAD (`git diff --cached`).
+func GetStagedDiff() (string, error) {
+	return runDiff("diff", "--cached")
+}
+
+// GetWorktreeDiff returns all uncommitted changes, staged and unstaged (`git diff HEAD`).
+func GetWorktreeDiff() (string, error) {
+	return runDiff("diff", "HEAD")
+}
+
+// GetRangeDiff returns the diff for a revision range such as `main..HEAD`.
+func GetRangeDiff(revRange string) (string, error) {
+	return runDiff("diff", revRange, "--")
+}
+
+// runDiff runs a git diff command, keeping stderr out of the returned diff.
+func runDiff(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git diff failed: %w\n%s", err, stderr.String())
+	}
+	return out.String(), nil
+}
+
+// CommitInfo identifies a commit visited by WalkLog.
+type CommitInfo struct {
+	SHA    string
+	Author string
+}
+
+// commitMarker starts the header line WalkLog asks git to print for each
+// commit. Patch lines always start with a diff prefix, so it cannot collide.
+const commitMarker = "gitai-commit "
+
+// WalkLog streams `git log -p` for revRange (the whole history if empty),
+// oldest commit first, calling fn with each commit and its patch. Merge
+// commits are diffed against their first parent, so content added while
+// resolving a merge is part of the patch.
+func WalkLog(revRange string, fn func(c CommitInfo, patch string) error) error {
+	args := []string{"log", "-p", "--diff-merges=first-parent", "--reverse", "--no-color", "--no-ext-diff", "--format=" + commitMarker + "%H%x09%an <%ae>"}
+	if revRange != "" {
+		args = append(args, revRange)
+	}
+	args = append(args, "--")
+
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var cur *CommitInfo
+	var patch strings.Builder
+	flush := func() error {
+		if cur == nil {
+			return nil
+		}
+		return fn(*cur, strings.TrimLeft(patch.String(), "\n"))
+	}
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, readErr := reader.ReadString('\n')
+		if strings.HasPrefix(line, commitMarker) {
+			if err := flush(); err != nil {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+				return err
+			}
+			sha, author, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, commitMarker)), "\t")
+			cur = &CommitInfo{SHA: sha, Author: author}
+			patch.Reset()
+		} else {
+			patch.WriteString(line)
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return readErr
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git log failed: %w\n%s", err, stderr.String())
+	}
+	return flush()
+}
+
+// ResolveCommit returns the full SHA of rev, failing if it is not a commit.
+func ResolveCommit(rev string) (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %q: %w", rev, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // GetStatus returns the output of `git status --porcelain`.
 func GetStatus() (string, error) {
 	cmd := exec.Command("git", "status", "--porcelain")
@@ -144,7 +257,7 @@ func Commit(files []string, message string) error {
 
 	// Then, commit *only* those files, leaving other staged files alone.
 	// Note: We don't use -a here. We commit what we just added.
-	commitArgs := append([]string{"commit", "-m", message, "--"})
+	commitArgs := []string{"commit", "-m", message, "--"}
 	commitArgs = append(commitArgs, files...)
 	if out, err := exec.Command("git", commitArgs...).CombinedOutput(); err != nil {
 		// Check if the error is "nothing to commit" and if so, return nil.
@@ -158,13 +271,146 @@ func Commit(files []string, message string) error {
 	return nil
 }
 
-// Push pushes the current branch to the remote repository.
+// GetConflictedFiles returns the unmerged files, relative to the repository
+// root. Paths are read NUL-separated so names with spaces or non-ASCII
+// characters come back as they are rather than C-quoted.
+func GetConflictedFiles() ([]string, error) {
+	out, err := exec.Command("git", "diff", "--name-only", "--diff-filter=U", "-z").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		// a file with several unmerged stages is listed once per stage
+		if name != "" && (len(files) == 0 || files[len(files)-1] != name) {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// Stage adds the specified files to the index, which also marks previously
+// conflicted files as resolved.
+func Stage(files []string) error {
+	if len(files) == 0 {
+		return errors.New("no files provided to stage")
+	}
+
+	addArgs := append([]string{"add", "--"}, files...)
+	if out, err := exec.Command("git", addArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage files: %w\n%s", err, string(out))
+	}
+	return nil
+}
+
+// Push pushes the current branch to remote, or to the branch's configured
+// upstream when remote is empty.
 // This simplified version returns Git's helpful error messages directly.
-func Push() error {
-	cmd := exec.Command("git", "push")
+func Push(remote string) error {
+	args := []string{"push"}
+	if remote != "" {
+		args = append(args, remote, "HEAD")
+	}
+	cmd := exec.Command("git", args...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("git push failed: %s", string(out))
 	}
 	return nil
 }
+
+// GetRemoteURLs returns the fetch URLs of every configured remote.
+func GetRemoteURLs() ([]string, error) {
+	out, err := exec.Command("git", "config", "--get-regexp", `^remote\..*\.url$`).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			// no remotes configured
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	var urls []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if _, url, ok := strings.Cut(line, " "); ok {
+			urls = append(urls, url)
+		}
+	}
+	return urls, nil
+}
+
+// CommitterIdent returns the committer as "Name <email>", the identity git
+// puts in a Signed-off-by trailer.
+func CommitterIdent() (string, error) {
+	out, err := exec.Command("git", "var", "GIT_COMMITTER_IDENT").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the committer identity: %w", err)
+	}
+	ident := strings.TrimSpace(string(out))
+	// drop the timestamp and zone after the email
+	if i := strings.LastIndex(ident, ">"); i >= 0 {
+		ident = ident[:i+1]
+	}
+	return ident, nil
+}
+
+// CurrentBranch returns the checked out branch, or "" on a detached HEAD.
+func CurrentBranch() (string, error) {
+	out, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read the current branch: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RecentCommitSubjects returns the subjects of the last n commits reachable
+// from HEAD, newest first; a repository without commits has none.
+func RecentCommitSubjects(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if _, err := ResolveCommit("HEAD"); err != nil {
+		return nil, nil
+	}
+
+	out, err := exec.Command("git", "log", fmt.Sprintf("-n%d", n), "--no-color", "--format=%s").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// RecentCommitMessages returns the full messages of the last n non-merge
+// commits reachable from HEAD, newest first.
+func RecentCommitMessages(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if _, err := ResolveCommit("HEAD"); err != nil {
+		return nil, nil
+	}
+
+	out, err := exec.Command("git", "log", fmt.Sprintf("-n%d", n), "--no-merges", "--no-color", "--format=%B%x00").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	var messages []string
+	for _, m := range strings.Split(string(out), "\x00") {
+		if m = strings.TrimSpace(m); m != "" {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,138 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initRepo creates an empty repository in a temporary directory, makes it the
+// working directory and isolates git from the user's global config.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Chdir(dir)
+	run(t, "init", "-q", "-b", "main")
+	run(t, "config", "user.name", "Jane Doe")
+	run(t, "config", "user.email", "jane@example.com")
+	run(t, "config", "commit.gpgsign", "false")
+	return dir
+}
+
+// run runs git in the working directory and returns its trimmed output.
+func run(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitFile writes content to name and commits it.
+func commitFile(t *testing.T, name, content, message string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, "add", "--", name)
+	run(t, "commit", "-q", "-m", message)
+	return run(t, "rev-parse", "HEAD")
+}
+
+func TestWalkLog(t *testing.T) {
+	initRepo(t)
+	first := commitFile(t, "a.txt", "one\n", "first")
+	run(t, "commit", "-q", "--allow-empty", "-m", "empty")
+	third := commitFile(t, "b.txt", "two\n", "third")
+
+	type visit struct {
+		commit CommitInfo
+		patch  string
+	}
+	walk := func(revRange string) []visit {
+		var got []visit
+		err := WalkLog(revRange, func(c CommitInfo, patch string) error {
+			got = append(got, visit{c, patch})
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	all := walk("")
+	if len(all) != 3 {
+		t.Fatalf("WalkLog() visited %d commits, want 3", len(all))
+	}
+	if all[0].commit.SHA != first || all[2].commit.SHA != third {
+		t.Errorf("WalkLog() order = %s..%s, want oldest first", all[0].commit.SHA, all[2].commit.SHA)
+	}
+	if all[0].commit.Author != "Jane Doe <jane@example.com>" {
+		t.Errorf("author = %q", all[0].commit.Author)
+	}
+	if !strings.HasPrefix(all[0].patch, "diff --git a/a.txt b/a.txt") || !strings.Contains(all[0].patch, "\n+one\n") {
+		t.Errorf("first patch = %q", all[0].patch)
+	}
+	if strings.TrimSpace(all[1].patch) != "" {
+		t.Errorf("empty commit has patch %q", all[1].patch)
+	}
+
+	if got := walk(first + "..HEAD"); len(got) != 2 || got[1].commit.SHA != third {
+		t.Errorf("WalkLog(range) = %+v, want the two newer commits", got)
+	}
+
+	if err := WalkLog("no-such-rev", func(CommitInfo, string) error { return nil }); err == nil {
+		t.Error("WalkLog() accepted an unknown revision")
+	}
+}
+
+func TestResolveCommit(t *testing.T) {
+	initRepo(t)
+	sha := commitFile(t, "a.txt", "one\n", "first")
+	if got, err := ResolveCommit("HEAD"); err != nil || got != sha {
+		t.Errorf("ResolveCommit(HEAD) = %q, %v; want %s", got, err, sha)
+	}
+	if _, err := ResolveCommit("0000000000000000000000000000000000000000"); err == nil {
+		t.Error("ResolveCommit() accepted a missing commit")
+	}
+}
+
+func TestGetConflictedFiles(t *testing.T) {
+	initRepo(t)
+	const spaced = "docs/read me é.txt"
+	commitFile(t, spaced, "base\n", "base")
+	commitFile(t, "clean.txt", "base\n", "clean")
+
+	run(t, "checkout", "-q", "-b", "other")
+	commitFile(t, spaced, "theirs\n", "theirs")
+	run(t, "checkout", "-q", "main")
+	commitFile(t, spaced, "ours\n", "ours")
+
+	if err := exec.Command("git", "merge", "-q", "other").Run(); err == nil {
+		t.Fatal("merge succeeded, want a conflict")
+	}
+
+	files, err := GetConflictedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != spaced {
+		t.Fatalf("GetConflictedFiles() = %q, want [%q]", files, spaced)
+	}
+	if _, err := os.Stat(files[0]); err != nil {
+		t.Errorf("conflicted path cannot be opened: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"os/exec"
 	"strings"
 )
@@ -21,3 +22,35 @@ func GetGitRoot() (string, error) {
 	// Trim whitespace and newlines from the command output
 	return strings.TrimSpace(string(output)), nil
 }
+
+// GetGitDir returns the absolute path of the repository's .git directory.
+func GetGitDir() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetHooksDir returns the directory git runs hooks from, honouring core.hooksPath.
+func GetHooksDir() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--path-format=absolute", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Version returns the installed git version, e.g. "2.43.0".
+func Version() (string, error) {
+	output, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	// "git version 2.43.0" or "git version 2.39.3 (Apple Git-146)"
+	fields := strings.Fields(string(output))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("unexpected git --version output: %q", string(output))
+	}
+	return fields[2], nil
+}
@@ -0,0 +1,143 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/pathmatch"
+)
+
+// FileName is the ignore file read from the repository root.
+const FileName = ".gitaiignore"
+
+type pattern struct {
+	glob   string
+	negate bool
+}
+
+// Matcher decides which paths are withheld from AI prompts. Patterns follow
+// gitignore syntax: later patterns override earlier ones and a leading "!"
+// re-includes a previously excluded path.
+type Matcher struct {
+	patterns []pattern
+}
+
+// New builds a matcher from gitignore-style lines; blank lines and comments
+// are skipped. A malformed pattern is an error rather than a pattern that
+// silently withholds nothing.
+func New(lines []string) (*Matcher, error) {
+	m := &Matcher{}
+	for i, l := range lines {
+		if err := m.add(l); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return m, nil
+}
+
+func (m *Matcher) add(line string) error {
+	l := strings.TrimSpace(line)
+	if l == "" || strings.HasPrefix(l, "#") {
+		return nil
+	}
+	p := pattern{glob: l}
+	if strings.HasPrefix(l, "!") {
+		p = pattern{glob: l[1:], negate: true}
+	} else if strings.HasPrefix(l, `\`) {
+		// escaped leading "#" or "!"
+		p.glob = l[1:]
+	}
+	if err := pathmatch.Validate(p.glob); err != nil {
+		return err
+	}
+	m.patterns = append(m.patterns, p)
+	return nil
+}
+
+// Load combines the configured exclude globs with the repo's .gitaiignore;
+// the file is applied last so it can re-include configured paths. Errors
+// name the config key or the file line of a malformed pattern.
+func Load(excludePaths []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, l := range excludePaths {
+		if err := m.add(l); err != nil {
+			return nil, fmt.Errorf("prompt.exclude_paths: %w", err)
+		}
+	}
+
+	root, err := git.GetGitRoot()
+	if err != nil {
+		return m, nil
+	}
+
+	path := filepath.Join(root, FileName)
+	fileLines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, l := range fileLines {
+		if err := m.add(l); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+	}
+	return m, nil
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return lines, nil
+}
+
+// Match reports whether the repo-relative path is withheld.
+func (m *Matcher) Match(path string) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if pathmatch.Match(p.glob, path) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// Split partitions files into those whose content may be sent and those
+// that are withheld.
+func (m *Matcher) Split(files []string) (included, withheld []string) {
+	for _, f := range files {
+		if m.Match(f) {
+			withheld = append(withheld, f)
+		} else {
+			included = append(included, f)
+		}
+	}
+	return included, withheld
+}
+
+// WithheldSummary describes withheld files for the prompt without their content.
+func WithheldSummary(files []string) string {
+	var b strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&b, "%s: file changed (content withheld)\n", f)
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package ignore
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{"unanchored glob at any depth", []string{"*.pem"}, "certs/prod/server.pem", true},
+		{"no pattern matches", []string{"*.pem"}, "main.go", false},
+		{"directory pattern covers its files", []string{"secrets/"}, "secrets/db.yaml", true},
+		{"anchored to the root", []string{"/config.yaml"}, "config.yaml", true},
+		{"anchored pattern is not unanchored", []string{"/config.yaml"}, "deploy/config.yaml", false},
+		{"inner slash anchors", []string{"deploy/*.yaml"}, "app/deploy/prod.yaml", false},
+		{"negation re-includes", []string{"*.env", "!example.env"}, "example.env", false},
+		{"negation keeps others excluded", []string{"*.env", "!example.env"}, "prod.env", true},
+		{"later pattern wins", []string{"!example.env", "*.env"}, "example.env", true},
+		{"comments are skipped", []string{"# *.go"}, "main.go", false},
+		{"blank lines are skipped", []string{"", "   "}, "main.go", false},
+		{"escaped hash", []string{`\#notes.txt`}, "#notes.txt", true},
+		{"escaped bang", []string{`\!important.txt`}, "!important.txt", true},
+		{"surrounding spaces are trimmed", []string{"  *.key  "}, "tls.key", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Match(tt.path); got != tt.want {
+				t.Errorf("Match(%q) with %q = %v, want %v", tt.path, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Malformed(t *testing.T) {
+	for _, lines := range [][]string{{"secrets/[abc"}, {"*.pem", "![z-a"}} {
+		if _, err := New(lines); err == nil {
+			t.Errorf("New(%q) accepted a malformed pattern", lines)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	m, err := New([]string{"*.lock", "vendor/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	included, withheld := m.Split([]string{"main.go", "go.lock", "vendor/x/y.go", "README.md"})
+	if !slices.Equal(included, []string{"main.go", "README.md"}) || !slices.Equal(withheld, []string{"go.lock", "vendor/x/y.go"}) {
+		t.Errorf("Split() = %q, %q", included, withheld)
+	}
+	if got := WithheldSummary(withheld); got != "go.lock: file changed (content withheld)\nvendor/x/y.go: file changed (content withheld)\n" {
+		t.Errorf("WithheldSummary() = %q", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if out, err := exec.Command("git", "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+
+	// the file comes after the config and can re-include configured paths
+	if err := os.WriteFile(FileName, []byte("# local secrets\n.env*\n!docs/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load([]string{"docs/", "*.pem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]bool{".env.local": true, "docs/guide.md": false, "certs/a.pem": true, "main.go": false} {
+		if got := m.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	if _, err := Load([]string{"[oops"}); err == nil || !strings.HasPrefix(err.Error(), "prompt.exclude_paths:") {
+		t.Errorf("Load() error = %v, want one naming prompt.exclude_paths", err)
+	}
+	if err := os.WriteFile(FileName, []byte("*.pem\n[oops\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), FileName+":2:") {
+		t.Errorf("Load() error = %v, want one naming %s:2", err, FileName)
+	}
+}
@@ -0,0 +1,224 @@
+// Package lint checks commit messages against the configured conventions and
+// repairs what can be fixed mechanically. It backs both the check of
+// generated messages and `gitai lint-msg`, the commit-msg hook.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"huseynovvusal/gitai/internal/ai"
+)
+
+// Action is what happens when a generated message has violations.
+type Action string
+
+const (
+	// ActionOff shows the message as generated.
+	ActionOff Action = "off"
+	// ActionRepair fixes what can be fixed mechanically.
+	ActionRepair Action = "repair"
+	// ActionReprompt repairs, then asks the provider once more to fix the rest.
+	ActionReprompt Action = "reprompt"
+)
+
+// Config is the `lint` section of the gitai config.
+type Config struct {
+	// Conventional requires a Conventional Commits header, type(scope)!: subject.
+	Conventional bool `mapstructure:"conventional"`
+	// Types and Scopes restrict the header; an empty Scopes allows any scope.
+	Types            []string `mapstructure:"types"`
+	Scopes           []string `mapstructure:"scopes"`
+	MaxSubjectLength int      `mapstructure:"max_subject_length"`
+	// HeaderPattern is a regular expression the header must match.
+	HeaderPattern string `mapstructure:"header_pattern"`
+	// FormatPattern is the header pattern of the format preset named by
+	// Format; it applies in addition to HeaderPattern.
+	Format        string `mapstructure:"-"`
+	FormatPattern string `mapstructure:"-"`
+	// BodyWrap is the longest allowed body line; 0 disables the check.
+	BodyWrap    int    `mapstructure:"body_wrap"`
+	OnViolation Action `mapstructure:"on_violation"`
+}
+
+// Defaults used by the config layer.
+var DefaultTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+const (
+	DefaultMaxSubjectLength = 72
+	DefaultBodyWrap         = 72
+)
+
+func (c Config) Validate() error {
+	switch c.OnViolation {
+	case "", ActionOff, ActionRepair, ActionReprompt:
+	default:
+		return fmt.Errorf("unknown on_violation %q (expected off|repair|reprompt)", c.OnViolation)
+	}
+	if _, err := regexp.Compile(c.HeaderPattern); err != nil {
+		return fmt.Errorf("header_pattern: %w", err)
+	}
+	if c.MaxSubjectLength < 0 || c.BodyWrap < 0 {
+		return fmt.Errorf("max_subject_length and body_wrap must not be negative")
+	}
+	return nil
+}
+
+// Violation is one broken rule. Line is 1-based, 0 for the whole message.
+type Violation struct {
+	Rule    string
+	Line    int
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Line == 0 {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", v.Line, v.Rule, v.Message)
+}
+
+// Rule names, as reported in violations.
+const (
+	RuleHeaderFormat  = "header-format"
+	RuleTypeEnum      = "type-enum"
+	RuleTypeEnglish   = "type-english"
+	RuleScopeEnum     = "scope-enum"
+	RuleSubjectEmpty  = "subject-empty"
+	RuleSubjectLength = "subject-max-length"
+	RuleBodyBlank     = "body-leading-blank"
+	RuleBodyLength    = "body-max-line-length"
+	RuleFence         = "no-markdown-fence"
+	RulePreamble      = "no-preamble"
+)
+
+var (
+	headerPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+	// translatedPattern matches a header whose type is not an ASCII word,
+	// e.g. a type translated along with a Japanese subject.
+	translatedPattern = regexp.MustCompile(`^([^\s:：()（）!]*[^\x00-\x7F][^\s:：()（）!]*)\s*(?:[(（][^()（）]*[)）])?!?\s*[:：]`)
+	// skipPattern matches messages git writes itself, which are not linted.
+	skipPattern = regexp.MustCompile(`^(Merge |Revert "|fixup! |squash! |amend! )`)
+)
+
+// Header is a parsed Conventional Commits header.
+type Header struct {
+	Type     string
+	Scope    string
+	Breaking bool
+	Subject  string
+}
+
+// ParseHeader parses a Conventional Commits header line.
+func ParseHeader(line string) (Header, bool) {
+	m := headerPattern.FindStringSubmatch(line)
+	if m == nil {
+		return Header{}, false
+	}
+	return Header{Type: m[1], Scope: m[2], Breaking: m[3] != "", Subject: m[4]}, true
+}
+
+// Lint returns the violations of msg, which should already be stripped of
+// comments (see Clean). Merge, revert and fixup messages are not checked.
+func Lint(cfg Config, msg string) []Violation {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	if skipPattern.MatchString(lines[0]) {
+		return nil
+	}
+
+	var vs []Violation
+	add := func(rule string, line int, format string, args ...any) {
+		vs = append(vs, Violation{Rule: rule, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "```") {
+			add(RuleFence, i+1, "markdown code fence")
+			break
+		}
+	}
+	if ai.IsPreamble(lines[0]) {
+		add(RulePreamble, 1, "%q is not part of a commit message", strings.TrimSpace(lines[0]))
+	}
+
+	header := lines[0]
+	if strings.TrimSpace(header) == "" {
+		add(RuleSubjectEmpty, 1, "the first line is empty")
+		return vs
+	}
+	if cfg.MaxSubjectLength > 0 {
+		if n := utf8.RuneCountInString(header); n > cfg.MaxSubjectLength {
+			add(RuleSubjectLength, 1, "header is %d characters, the limit is %d", n, cfg.MaxSubjectLength)
+		}
+	}
+
+	if cfg.Conventional {
+		h, ok := ParseHeader(header)
+		switch {
+		case !ok:
+			if m := translatedPattern.FindStringSubmatch(header); m != nil {
+				add(RuleTypeEnglish, 1, "type %q must stay in English, e.g. feat or fix", m[1])
+			} else {
+				add(RuleHeaderFormat, 1, "header must look like type(scope): subject")
+			}
+		default:
+			if len(cfg.Types) > 0 && !slices.Contains(cfg.Types, h.Type) {
+				add(RuleTypeEnum, 1, "type %q is not one of %s", h.Type, strings.Join(cfg.Types, ", "))
+			}
+			if h.Scope != "" && len(cfg.Scopes) > 0 && !slices.Contains(cfg.Scopes, h.Scope) {
+				add(RuleScopeEnum, 1, "scope %q is not one of %s", h.Scope, strings.Join(cfg.Scopes, ", "))
+			}
+			if strings.TrimSpace(h.Subject) == "" {
+				add(RuleSubjectEmpty, 1, "subject after %q is empty", h.Type+":")
+			}
+		}
+	}
+
+	if cfg.FormatPattern != "" {
+		if re, err := regexp.Compile(cfg.FormatPattern); err == nil && !re.MatchString(header) {
+			add(RuleHeaderFormat, 1, "header does not follow the %s format", cfg.Format)
+		}
+	}
+	if cfg.HeaderPattern != "" {
+		if re, err := regexp.Compile(cfg.HeaderPattern); err == nil && !re.MatchString(header) {
+			add(RuleHeaderFormat, 1, "header does not match %s", cfg.HeaderPattern)
+		}
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		add(RuleBodyBlank, 2, "leave a blank line between the subject and the body")
+	}
+	if cfg.BodyWrap > 0 {
+		trailers := ai.TrailerStart(lines)
+		for i, l := range lines[1:] {
+			// a line without spaces (a URL) cannot be wrapped; trailers are not wrapped
+			if n := utf8.RuneCountInString(l); n > cfg.BodyWrap && strings.ContainsRune(strings.TrimSpace(l), ' ') && i+1 < trailers {
+				add(RuleBodyLength, i+2, "line is %d characters, wrap at %d", n, cfg.BodyWrap)
+			}
+		}
+	}
+	return vs
+}
+
+// scissors is the line below which `git commit --verbose` puts the diff.
+const scissors = "# ------------------------ >8 ------------------------"
+
+// Clean prepares a commit message file for linting the way git's default
+// cleanup does: it drops everything below the scissors line and comment
+// lines, and trims surrounding blank lines.
+func Clean(msg string) string {
+	msg = strings.ReplaceAll(msg, "\r\n", "\n")
+	if i := strings.Index(msg, scissors); i >= 0 {
+		msg = msg[:i]
+	}
+	var kept []string
+	for _, l := range strings.Split(msg, "\n") {
+		if !strings.HasPrefix(l, "#") {
+			kept = append(kept, strings.TrimRight(l, " \t"))
+		}
+	}
+	return strings.Trim(strings.Join(kept, "\n"), "\n")
+}
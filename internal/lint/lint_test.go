@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testConfig = Config{
+	Conventional:     true,
+	Types:            DefaultTypes,
+	MaxSubjectLength: 50,
+	BodyWrap:         40,
+	OnViolation:      ActionRepair,
+}
+
+func rules(vs []Violation) []string {
+	var out []string
+	for _, v := range vs {
+		out = append(out, v.Rule)
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *Config // nil uses testConfig
+		msg  string
+		want []string
+	}{
+		{name: "valid", msg: "feat(api): add endpoint\n\nExplain why.", want: nil},
+		{name: "breaking", msg: "feat!: drop v1", want: nil},
+		{name: "not conventional", msg: "Add endpoint", want: []string{RuleHeaderFormat}},
+		{name: "unknown type", msg: "feature: add endpoint", want: []string{RuleTypeEnum}},
+		{name: "translated type", msg: "修正(auth): ログアウト時のクラッシュを修正", want: []string{RuleTypeEnglish}},
+		{name: "translated type, full-width colon", msg: "機能：検索を追加", want: []string{RuleTypeEnglish}},
+		{name: "english type, japanese subject", msg: "fix(auth): ログアウト時のクラッシュを修正", want: nil},
+		{name: "scope not allowed", cfg: &Config{Conventional: true, Scopes: []string{"api"}}, msg: "fix(ui): align", want: []string{RuleScopeEnum}},
+		{name: "empty subject", msg: "fix: ", want: []string{RuleSubjectEmpty}},
+		{name: "long header", msg: "fix: " + strings.Repeat("a", 50), want: []string{RuleSubjectLength}},
+		{name: "no blank line", msg: "fix: a\nbody", want: []string{RuleBodyBlank}},
+		{name: "long body line", msg: "fix: a\n\n" + strings.Repeat("word ", 10), want: []string{RuleBodyLength}},
+		{name: "long url is fine", msg: "fix: a\n\nhttps://example.com/" + strings.Repeat("x", 60), want: nil},
+		{name: "trailer-like body line", msg: "fix: a\n\nNote: " + strings.Repeat("word ", 10) + "\n\nMore prose.", want: []string{RuleBodyLength}},
+		{name: "long trailer is fine", msg: "fix: a\n\nCo-authored-by: " + strings.Repeat("x", 60) + " <x@example.com>", want: nil},
+		{name: "fence and preamble", msg: "Here is your commit message:\n```\nfix: a\n```", want: []string{RuleFence, RulePreamble, RuleHeaderFormat, RuleBodyBlank}},
+		{name: "merge is skipped", msg: "Merge branch 'main'", want: nil},
+		{name: "plain style", cfg: &Config{MaxSubjectLength: 50}, msg: "Add endpoint", want: nil},
+		{name: "format pattern", cfg: &Config{FormatPattern: `^\[[A-Z]+-\d+\] `, Format: "team"}, msg: "[ABC-1] Add endpoint", want: nil},
+		{name: "format and header pattern", cfg: &Config{FormatPattern: `^[A-Z]`, Format: "plain", HeaderPattern: `ABC-\d+`}, msg: "add endpoint", want: []string{RuleHeaderFormat, RuleHeaderFormat}},
+		{name: "header pattern mismatch", cfg: &Config{HeaderPattern: `^\[[A-Z]+-\d+\] `}, msg: "Add endpoint", want: []string{RuleHeaderFormat}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig
+			if tt.cfg != nil {
+				cfg = *tt.cfg
+			}
+			if got := rules(Lint(cfg, tt.msg)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       string
+		want      string
+		remaining []string
+	}{
+		{
+			name: "preamble and fence",
+			msg:  "Sure! Here is your commit message:\n\n```\nfeat(api): add endpoint\n```\n",
+			want: "feat(api): add endpoint",
+		},
+		{
+			name: "header punctuation and case",
+			msg:  "Fix(ui):align button\nBody text",
+			want: "fix(ui): align button\n\nBody text",
+		},
+		{
+			name: "wrap bullets",
+			msg:  "fix: a\n\n- one two three four five six seven eight nine\n\n\n\nSigned-off-by: Someone With A Long Name <someone@example.com>",
+			want: "fix: a\n\n- one two three four five six seven\n  eight nine\n\nSigned-off-by: Someone With A Long Name <someone@example.com>",
+		},
+		{
+			name: "human body is kept",
+			msg:  "feat(cache): add response cache\n\nThis commit adds a disk cache.",
+			want: "feat(cache): add response cache\n\nThis commit adds a disk cache.",
+		},
+		{
+			name:      "subject too long needs a person",
+			msg:       "fix: " + strings.Repeat("a", 50),
+			want:      "fix: " + strings.Repeat("a", 50),
+			remaining: []string{RuleSubjectLength},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, vs := Repair(testConfig, tt.msg)
+			if got != tt.want {
+				t.Errorf("Repair =\n%q\nwant\n%q", got, tt.want)
+			}
+			if r := rules(vs); !reflect.DeepEqual(r, tt.remaining) {
+				t.Errorf("remaining = %v, want %v", r, tt.remaining)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	in := "feat: add x\r\n\r\nbody  \n# Please enter the commit message\n#\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n"
+	if got, want := Clean(in), "feat: add x\n\nbody"; got != want {
+		t.Errorf("Clean = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+
+	"huseynovvusal/gitai/internal/ai"
+)
+
+// missingSpacePattern matches "feat:subject" and "feat(x):subject".
+var missingSpacePattern = regexp.MustCompile(`^([a-zA-Z]+(?:\([^()]*\))?!?):(\S)`)
+
+// Repair fixes the violations that need no judgement: it strips what models
+// put around a message (see ai.CleanMessage), fixes the header punctuation
+// and type case, and rewraps long body lines. It returns the repaired
+// message and the violations that remain.
+func Repair(cfg Config, msg string) (string, []Violation) {
+	msg = ai.CleanMessage(msg)
+	if msg == "" {
+		return "", Lint(cfg, "")
+	}
+
+	if cfg.Conventional {
+		subject, body, hasBody := strings.Cut(msg, "\n")
+		msg = repairHeader(subject)
+		if hasBody {
+			msg += "\n" + body
+		}
+	}
+	msg = ai.WrapBody(msg, cfg.BodyWrap)
+
+	return msg, Lint(cfg, msg)
+}
+
+func repairHeader(h string) string {
+	h = missingSpacePattern.ReplaceAllString(h, "$1: $2")
+	if parsed, ok := ParseHeader(h); ok && parsed.Type != strings.ToLower(parsed.Type) {
+		h = strings.ToLower(parsed.Type) + h[len(parsed.Type):]
+	}
+	return h
+}
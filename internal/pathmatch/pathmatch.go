@@ -0,0 +1,92 @@
+package pathmatch
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Match reports whether the slash-separated, repo-relative name matches the
+// gitignore-style glob pattern:
+//   - "**" matches zero or more directories
+//   - a pattern without a slash matches at any depth ("*.pem", ".env*")
+//   - a pattern with a leading or inner slash is anchored to the repo root
+//   - a pattern matching a directory also matches everything below it, and a
+//     trailing slash restricts the pattern to directories
+func Match(pattern, name string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return false
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+
+	pp := strings.Split(pattern, "/")
+	np := strings.Split(strings.TrimPrefix(path.Clean(name), "./"), "/")
+
+	// try the full path first, then each parent directory
+	last := len(np)
+	if dirOnly {
+		last--
+	}
+	for i := last; i >= 1; i-- {
+		if matchSegments(pp, np[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate reports a malformed pattern, such as an unclosed "[", which
+// would otherwise never match anything.
+func Validate(pattern string) error {
+	for _, seg := range strings.Split(strings.Trim(strings.TrimSpace(pattern), "/"), "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MatchAny reports whether name matches any of the patterns.
+func MatchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if Match(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pp, np []string) bool {
+	for len(pp) > 0 {
+		if pp[0] == "**" {
+			pp = pp[1:]
+			if len(pp) == 0 {
+				return true
+			}
+			for i := 0; i <= len(np); i++ {
+				if matchSegments(pp, np[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(np) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pp[0], np[0]); err != nil || !ok {
+			return false
+		}
+		pp, np = pp[1:], np[1:]
+	}
+	return len(np) == 0
+}
@@ -0,0 +1,45 @@
+package pathmatch
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.pem", "certs/server.pem", true},
+		{".env*", ".env.local", true},
+		{".env*", "config/.env", true},
+		{"secrets/", "secrets/db.yaml", true},
+		{"secrets/", "secrets", false},
+		{"vendor", "vendor/github.com/x/y.go", true},
+		{"vendor/**", "vendor/a/b.go", true},
+		{"/go.sum", "go.sum", true},
+		{"/go.sum", "tools/go.sum", false},
+		{"services/billing/**", "services/billing/api/handler.go", true},
+		{"services/billing/**", "services/billingx/main.go", false},
+		{"**/testdata/*.json", "internal/security/testdata/fp.json", true},
+		{"internal/*.go", "internal/security/rules.go", false},
+		{"", "main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, p := range []string{"*.pem", "/secrets/**", `\#x`, "a/[bc]/d"} {
+		if err := Validate(p); err != nil {
+			t.Errorf("Validate(%q) = %v", p, err)
+		}
+	}
+	for _, p := range []string{"[abc", "a/[z-/b"} {
+		if err := Validate(p); err == nil {
+			t.Errorf("Validate(%q) accepted a malformed pattern", p)
+		}
+	}
+}
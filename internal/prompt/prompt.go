@@ -0,0 +1,152 @@
+// Package prompt assembles the commit prompt: it finds the templates
+// configured for the repository and gathers the data they render.
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/config"
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/scope"
+	"huseynovvusal/gitai/internal/security"
+	"huseynovvusal/gitai/internal/trailer"
+)
+
+// RepoDir holds per-repository templates, relative to the repository root.
+const RepoDir = ".gitai/prompts"
+
+// Template file names looked up in RepoDir.
+const (
+	SystemFile = "system.tmpl"
+	CommitFile = "commit.tmpl"
+)
+
+// BuiltIn is the source reported for a template that was not overridden.
+const BuiltIn = "built-in"
+
+// Templates are the parsed prompt templates and where each came from.
+type Templates struct {
+	*ai.PromptTemplates
+	SystemSource string
+	CommitSource string
+}
+
+// Load resolves each template in order: the path configured in
+// prompt.system_template / prompt.commit_template (relative paths are taken
+// from the repository root), then RepoDir in the repository, where a
+// variant for ai.language such as system.de.tmpl wins, then the built-in
+// prompt.
+func Load(cfg *config.Config) (*Templates, error) {
+	root, _ := git.GetGitRoot()
+	lang := cfg.Language().Code
+
+	system, systemSrc, err := find(cfg.Prompt.SystemTemplate, root, SystemFile, lang)
+	if err != nil {
+		return nil, err
+	}
+	commit, commitSrc, err := find(cfg.Prompt.CommitTemplate, root, CommitFile, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := ai.NewPromptTemplates(system, commit)
+	if err != nil {
+		return nil, err
+	}
+	return &Templates{PromptTemplates: t, SystemSource: systemSrc, CommitSource: commitSrc}, nil
+}
+
+func find(configured, root, name, lang string) (text, source string, err error) {
+	if configured != "" {
+		path := config.ExpandHome(configured)
+		if !filepath.IsAbs(path) && root != "" {
+			path = filepath.Join(root, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// a configured template that cannot be read is a mistake, not a fallback
+			return "", "", fmt.Errorf("prompt template: %w", err)
+		}
+		return string(data), path, nil
+	}
+
+	if root != "" {
+		names := []string{name}
+		if lang != "" && lang != "en" {
+			// system.tmpl -> system.de.tmpl
+			ext := filepath.Ext(name)
+			names = []string{strings.TrimSuffix(name, ext) + "." + lang + ext, name}
+		}
+		for _, n := range names {
+			path := filepath.Join(root, RepoDir, n)
+			if data, err := os.ReadFile(path); err == nil {
+				return string(data), path, nil
+			}
+		}
+	}
+	return "", BuiltIn, nil
+}
+
+// Gather collects the template data for a commit of files. diff and status
+// are passed in because callers withhold or redact parts of them first.
+// Branch, history and scopes are context only, so failures to read them
+// leave the fields empty.
+func Gather(cfg *config.Config, files []string, diff, status string) ai.PromptData {
+	branch, _ := git.CurrentBranch()
+	recent, _ := git.RecentCommitSubjects(cfg.Prompt.RecentCommits)
+	examples, hints := LoadStyle(cfg.Prompt.StyleExamples)
+
+	// only Conventional Commits headers have a scope
+	var shared string
+	var scopes []string
+	if cfg.Preset().Conventional {
+		root, _ := git.GetGitRoot()
+		shared, scopes = scope.NewResolver(cfg.Scope, root).Resolve(files)
+	}
+
+	var language string
+	if lang := cfg.Language(); !lang.IsEnglish() {
+		language = lang.Name
+	}
+
+	var formatPrompt string
+	var formatExamples []string
+	if preset := cfg.Preset(); preset.Prompt != "" {
+		formatPrompt, formatExamples = preset.Prompt, preset.Examples
+	}
+
+	return ai.PromptData{
+		Diff:                diff,
+		Status:              status,
+		Branch:              branch,
+		RecentCommits:       recent,
+		Files:               files,
+		Ticket:              trailer.Ticket(cfg.Ticket, branch),
+		FormatPrompt:        formatPrompt,
+		FormatExamples:      formatExamples,
+		StyleExamples:       examples,
+		StyleHints:          hints,
+		Scope:               shared,
+		Scopes:              scopes,
+		Language:            language,
+		LanguageInstruction: cfg.Language().Instruction(cfg.Preset().Conventional),
+	}
+}
+
+// Redact applies security.policy=redact to diff as gitai suggest does before
+// calling the provider, and reports whether anything was replaced. Under the
+// other policies the diff is returned as it is.
+func Redact(cfg *config.Config, diff string) (string, bool, error) {
+	if cfg.Security.Policy != security.PolicyRedact {
+		return diff, false, nil
+	}
+	redacted, err := security.RedactDiff(diff, cfg.Security, cfg.ScanProvider())
+	if err != nil {
+		return "", false, err
+	}
+	return redacted, redacted != diff, nil
+}
@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"regexp"
+	"strings"
+
+	"huseynovvusal/gitai/internal/git"
+)
+
+// sampleFactor is how many commits are read per requested example, leaving
+// room to skip fixups, reverts and duplicates.
+const sampleFactor = 4
+
+// maxExampleLines caps each example so that one long commit cannot crowd
+// out the others or the diff.
+const maxExampleLines = 12
+
+// minHintExamples is the fewest examples from which conventions are inferred.
+const minHintExamples = 3
+
+var (
+	// skipPattern matches messages that say nothing about the house style.
+	skipPattern         = regexp.MustCompile(`^(fixup!|squash!|amend!|Merge |Revert "|(?i:wip)\b)`)
+	conventionalPattern = regexp.MustCompile(`^(\S+ )?[a-z]+(\([^)]+\))?!?: `)
+	scopedPattern       = regexp.MustCompile(`^(\S+ )?[a-z]+\([^)]+\)!?: `)
+	gitmojiPattern      = regexp.MustCompile(`^(:[a-z0-9_+-]+:|\p{So})`)
+	ticketPrefixPattern = regexp.MustCompile(`^\[?[A-Z][A-Z0-9]+-[0-9]+\]?[:\s]`)
+)
+
+// LoadStyle samples the branch history for up to n examples of the
+// repository's commit style, and the conventions they share.
+func LoadStyle(n int) (examples, hints []string) {
+	if n <= 0 {
+		return nil, nil
+	}
+	messages, err := git.RecentCommitMessages(n * sampleFactor)
+	if err != nil {
+		return nil, nil
+	}
+	examples = StyleExamples(messages, n)
+	return examples, StyleHints(examples)
+}
+
+// StyleExamples picks up to n distinct messages, newest first, skipping
+// fixups, merges, reverts and work in progress, and truncating long bodies.
+func StyleExamples(messages []string, n int) []string {
+	seen := map[string]bool{}
+	var examples []string
+	for _, m := range messages {
+		if len(examples) == n {
+			break
+		}
+		m = strings.TrimSpace(m)
+		subject, _, _ := strings.Cut(m, "\n")
+		if m == "" || skipPattern.MatchString(subject) || seen[subject] {
+			continue
+		}
+		seen[subject] = true
+
+		if lines := strings.Split(m, "\n"); len(lines) > maxExampleLines {
+			m = strings.Join(lines[:maxExampleLines], "\n") + "\n..."
+		}
+		examples = append(examples, m)
+	}
+	return examples
+}
+
+// StyleHints names the conventions followed by most of examples, so the
+// model does not have to infer them from a handful of samples.
+func StyleHints(examples []string) []string {
+	if len(examples) < minHintExamples {
+		return nil
+	}
+
+	var conventional, scoped, gitmoji, ticket, period, body int
+	for _, m := range examples {
+		subject, rest, _ := strings.Cut(m, "\n")
+		if conventionalPattern.MatchString(subject) {
+			conventional++
+		}
+		if scopedPattern.MatchString(subject) {
+			scoped++
+		}
+		if gitmojiPattern.MatchString(subject) {
+			gitmoji++
+		}
+		if ticketPrefixPattern.MatchString(subject) {
+			ticket++
+		}
+		if strings.HasSuffix(subject, ".") {
+			period++
+		}
+		if strings.TrimSpace(rest) != "" {
+			body++
+		}
+	}
+
+	n := len(examples)
+	most := func(count int) bool { return count*2 > n }
+	few := func(count int) bool { return count*4 < n }
+
+	var hints []string
+	switch {
+	case most(conventional) && most(scoped):
+		hints = append(hints, "Subjects use Conventional Commits with a scope: type(scope): description")
+	case most(conventional):
+		hints = append(hints, "Subjects use Conventional Commits types, usually without a scope")
+	case few(conventional):
+		hints = append(hints, "Subjects do not start with a Conventional Commits type")
+	}
+	if most(gitmoji) {
+		hints = append(hints, "Subjects start with a gitmoji")
+	}
+	if most(ticket) {
+		hints = append(hints, "Subjects start with an issue key such as ABC-123")
+	}
+	if most(period) {
+		hints = append(hints, "Subjects end with a period")
+	}
+	if few(body) {
+		hints = append(hints, "Most commits have a subject line only, without a body")
+	}
+	return hints
+}
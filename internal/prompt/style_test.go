@@ -0,0 +1,74 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStyleExamples(t *testing.T) {
+	long := "feat: long\n\n" + strings.Repeat("- line\n", 20)
+	messages := []string{
+		"fixup! feat: add login",
+		"feat(auth): add login",
+		"Merge branch 'main' into feature",
+		"WIP",
+		"feat(auth): add login",
+		`Revert "fix: typo"`,
+		long,
+		"fix(ui): align button",
+	}
+
+	got := StyleExamples(messages, 3)
+	if len(got) != 3 {
+		t.Fatalf("got %d examples, want 3: %q", len(got), got)
+	}
+	if got[0] != "feat(auth): add login" || got[2] != "fix(ui): align button" {
+		t.Errorf("examples = %q", got)
+	}
+	if lines := strings.Split(got[1], "\n"); len(lines) != maxExampleLines+1 || lines[maxExampleLines] != "..." {
+		t.Errorf("long example was not truncated: %q", got[1])
+	}
+}
+
+func TestStyleHints(t *testing.T) {
+	tests := []struct {
+		name     string
+		examples []string
+		want     []string
+	}{
+		{
+			name:     "conventional with scopes",
+			examples: []string{"feat(api): add x", "fix(ui): y", "chore(deps): bump z"},
+			want:     []string{"Subjects use Conventional Commits with a scope: type(scope): description", "Most commits have a subject line only, without a body"},
+		},
+		{
+			name:     "gitmoji without scopes",
+			examples: []string{"✨ feat: add x", "🐛 fix: y", ":memo: docs: z\n\nmore detail"},
+			want:     []string{"Subjects use Conventional Commits types, usually without a scope", "Subjects start with a gitmoji"},
+		},
+		{
+			name:     "ticket prefixes and periods",
+			examples: []string{"ABC-1 Add login.", "[ABC-2] Fix crash.", "ABC-3: Update docs.", "Tidy up"},
+			want: []string{
+				"Subjects do not start with a Conventional Commits type",
+				"Subjects start with an issue key such as ABC-123",
+				"Subjects end with a period",
+				"Most commits have a subject line only, without a body",
+			},
+		},
+		{
+			name:     "too few examples",
+			examples: []string{"feat: x", "fix: y"},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StyleHints(tt.examples); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StyleHints = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
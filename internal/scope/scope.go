@@ -0,0 +1,148 @@
+// Package scope derives the Conventional Commits scope of a change from the
+// paths it touches, for monorepos where the scope names a component.
+package scope
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"huseynovvusal/gitai/internal/lint"
+	"huseynovvusal/gitai/internal/pathmatch"
+)
+
+// Config is the `scope` section of the gitai config.
+type Config struct {
+	// Paths map gitignore-style globs to scope names; the first match wins.
+	Paths []Mapping `mapstructure:"paths"`
+	// Auto names files without a mapping after the nearest directory below
+	// the repository root that holds a go.mod or package.json.
+	Auto bool `mapstructure:"auto"`
+	// Enforce rewrites the scope of generated messages instead of only
+	// suggesting it in the prompt.
+	Enforce bool `mapstructure:"enforce"`
+}
+
+// Mapping assigns Scope to the files matching Pattern, e.g.
+// "services/billing/**" to "billing".
+type Mapping struct {
+	Pattern string `mapstructure:"pattern"`
+	Scope   string `mapstructure:"scope"`
+}
+
+func (c Config) Validate() error {
+	for i, m := range c.Paths {
+		if strings.TrimSpace(m.Pattern) == "" || strings.TrimSpace(m.Scope) == "" {
+			return fmt.Errorf("paths[%d]: needs a pattern and a scope", i)
+		}
+		if strings.ContainsAny(m.Scope, "()\n") {
+			return fmt.Errorf("paths[%d]: scope %q cannot contain parentheses", i, m.Scope)
+		}
+	}
+	return nil
+}
+
+// moduleFiles mark the root of a Go module or a JavaScript package.
+var moduleFiles = []string{"go.mod", "package.json"}
+
+// Resolver maps repository-relative paths to scopes.
+type Resolver struct {
+	cfg  Config
+	root string
+	// dirs caches the module lookup per directory
+	dirs map[string]string
+}
+
+// NewResolver returns a resolver for the repository at root; without a
+// root, only the configured paths apply.
+func NewResolver(cfg Config, root string) *Resolver {
+	return &Resolver{cfg: cfg, root: root, dirs: map[string]string{}}
+}
+
+// Scope returns the scope of one file, or "" if it has none.
+func (r *Resolver) Scope(file string) string {
+	file = filepath.ToSlash(file)
+	for _, m := range r.cfg.Paths {
+		if pathmatch.Match(m.Pattern, file) {
+			return m.Scope
+		}
+	}
+	if !r.cfg.Auto || r.root == "" {
+		return ""
+	}
+	return r.module(path.Dir(file))
+}
+
+// module returns the base name of the closest directory at or above dir,
+// below the repository root, that holds a module file.
+func (r *Resolver) module(dir string) string {
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	if s, ok := r.dirs[dir]; ok {
+		return s
+	}
+
+	s := ""
+	for _, name := range moduleFiles {
+		if _, err := os.Stat(filepath.Join(r.root, filepath.FromSlash(dir), name)); err == nil {
+			s = path.Base(dir)
+			break
+		}
+	}
+	if s == "" {
+		s = r.module(path.Dir(dir))
+	}
+	r.dirs[dir] = s
+	return s
+}
+
+// Resolve returns the scope shared by all files, or "" when they have
+// different scopes or some have none, together with the distinct scopes
+// found, sorted.
+func (r *Resolver) Resolve(files []string) (string, []string) {
+	seen := map[string]bool{}
+	shared := true
+	for _, f := range files {
+		s := r.Scope(f)
+		if s == "" {
+			shared = false
+			continue
+		}
+		seen[s] = true
+	}
+
+	scopes := make([]string, 0, len(seen))
+	for s := range seen {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+
+	if shared && len(scopes) == 1 {
+		return scopes[0], scopes
+	}
+	return "", scopes
+}
+
+// Apply sets the scope of a Conventional Commits message to scope. Messages
+// without such a header are returned unchanged.
+func Apply(msg, scope string) string {
+	header, rest, hasBody := strings.Cut(msg, "\n")
+	h, ok := lint.ParseHeader(header)
+	if !ok || scope == "" {
+		return msg
+	}
+
+	header = h.Type + "(" + scope + ")"
+	if h.Breaking {
+		header += "!"
+	}
+	header += ": " + h.Subject
+	if hasBody {
+		return header + "\n" + rest
+	}
+	return header
+}
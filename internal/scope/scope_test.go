@@ -0,0 +1,123 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root,
+		"go.mod",
+		"services/billing/go.mod",
+		"services/billing/internal/invoice/invoice.go",
+		"web/dashboard/package.json",
+		"web/dashboard/src/app.ts",
+	)
+
+	cfg := Config{
+		Auto: true,
+		Paths: []Mapping{
+			{Pattern: "docs/**", Scope: "docs"},
+			{Pattern: "services/billing/api/**", Scope: "billing-api"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		files      []string
+		wantScope  string
+		wantScopes []string
+	}{
+		{
+			name:       "go module",
+			cfg:        cfg,
+			files:      []string{"services/billing/internal/invoice/invoice.go", "services/billing/go.mod"},
+			wantScope:  "billing",
+			wantScopes: []string{"billing"},
+		},
+		{
+			name:       "package.json",
+			cfg:        cfg,
+			files:      []string{"web/dashboard/src/app.ts"},
+			wantScope:  "dashboard",
+			wantScopes: []string{"dashboard"},
+		},
+		{
+			name:       "mapping wins over the module",
+			cfg:        cfg,
+			files:      []string{"services/billing/api/openapi.yaml"},
+			wantScope:  "billing-api",
+			wantScopes: []string{"billing-api"},
+		},
+		{
+			name:       "several components",
+			cfg:        cfg,
+			files:      []string{"docs/billing.md", "services/billing/go.mod", "web/dashboard/src/app.ts"},
+			wantScopes: []string{"billing", "dashboard", "docs"},
+		},
+		{
+			name:       "root module is no scope",
+			cfg:        cfg,
+			files:      []string{"main.go", "services/billing/go.mod"},
+			wantScopes: []string{"billing"},
+		},
+		{
+			name:       "auto off",
+			cfg:        Config{Paths: cfg.Paths},
+			files:      []string{"services/billing/go.mod"},
+			wantScopes: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, scopes := NewResolver(tt.cfg, root).Resolve(tt.files)
+			if scope != tt.wantScope || !slices.Equal(scopes, tt.wantScopes) {
+				t.Errorf("Resolve() = %q, %q; want %q, %q", scope, scopes, tt.wantScope, tt.wantScopes)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		msg, scope, want string
+	}{
+		{"feat: add invoices", "billing", "feat(billing): add invoices"},
+		{"fix(api)!: drop v1\n\nbody", "billing", "fix(billing)!: drop v1\n\nbody"},
+		{"Add invoices", "billing", "Add invoices"},
+		{"feat(api): x", "", "feat(api): x"},
+	}
+	for _, tt := range tests {
+		if got := Apply(tt.msg, tt.scope); got != tt.want {
+			t.Errorf("Apply(%q, %q) = %q, want %q", tt.msg, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Config{Paths: []Mapping{{Pattern: "a/**", Scope: "a"}}}).Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+	for _, m := range []Mapping{{Pattern: "a/**"}, {Scope: "a"}, {Pattern: "a/**", Scope: "a(b)"}} {
+		if err := (Config{Paths: []Mapping{m}}).Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", m)
+		}
+	}
+}
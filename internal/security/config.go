@@ -0,0 +1,183 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"huseynovvusal/gitai/internal/git"
+)
+
+// RulesFileName is the repo-level rules file, relative to the repository root.
+const RulesFileName = ".gitai/security.yaml"
+
+// Policy decides what happens when a diff contains findings.
+type Policy string
+
+const (
+	// PolicyWarn asks the user whether to send, redact, or abort.
+	PolicyWarn Policy = "warn"
+	// PolicyRedact replaces matched values with placeholders without asking.
+	PolicyRedact Policy = "redact"
+	// PolicyBlock refuses to send the diff.
+	PolicyBlock Policy = "block"
+)
+
+// DefaultPolicy is used when no policy is configured: nothing that looks
+// like a secret is sent unless the user opts in to warn.
+const DefaultPolicy = PolicyRedact
+
+// RuleConfig describes a custom rule in the config or the repo rules file.
+type RuleConfig struct {
+	ID          string   `mapstructure:"id" yaml:"id"`
+	Description string   `mapstructure:"description" yaml:"description"`
+	Regex       string   `mapstructure:"regex" yaml:"regex"`
+	Category    Category `mapstructure:"category" yaml:"category"`
+	Severity    Severity `mapstructure:"severity" yaml:"severity"`
+	SecretGroup int      `mapstructure:"secret_group" yaml:"secret_group"`
+	MinEntropy  float64  `mapstructure:"min_entropy" yaml:"min_entropy"`
+}
+
+// PIIConfig controls the PII rule category.
+type PIIConfig struct {
+	// Providers lists the providers PII rules are enforced for; nil means
+	// DefaultPIIProviders and an empty list disables PII detection.
+	Providers []string `mapstructure:"providers" yaml:"providers"`
+}
+
+// Config is the `security` section of the gitai config. The repo rules file
+// uses the same layout without the `security:` key.
+type Config struct {
+	Policy            Policy       `mapstructure:"policy" yaml:"policy"`
+	Keywords          []string     `mapstructure:"keywords" yaml:"keywords"`
+	Rules             []RuleConfig `mapstructure:"rules" yaml:"rules"`
+	DisabledRules     []string     `mapstructure:"disabled_rules" yaml:"disabled_rules"`
+	AllowPaths        []string     `mapstructure:"allow_paths" yaml:"allow_paths"`
+	AllowFingerprints []string     `mapstructure:"allow_fingerprints" yaml:"allow_fingerprints"`
+	PII               PIIConfig    `mapstructure:"pii" yaml:"pii"`
+}
+
+// PIIEnforcedFor reports whether PII rules apply to diffs sent to provider.
+func (c Config) PIIEnforcedFor(provider string) bool {
+	providers := c.PII.Providers
+	if providers == nil {
+		providers = DefaultPIIProviders
+	}
+	for _, p := range providers {
+		if strings.EqualFold(strings.TrimSpace(p), provider) {
+			return true
+		}
+	}
+	return false
+}
+
+// merge appends the lists of other to c; a policy set in other wins.
+func (c *Config) merge(other Config) {
+	if other.Policy != "" {
+		c.Policy = other.Policy
+	}
+	if other.PII.Providers != nil {
+		c.PII.Providers = other.PII.Providers
+	}
+	c.Keywords = append(c.Keywords, other.Keywords...)
+	c.Rules = append(c.Rules, other.Rules...)
+	c.DisabledRules = append(c.DisabledRules, other.DisabledRules...)
+	c.AllowPaths = append(c.AllowPaths, other.AllowPaths...)
+	c.AllowFingerprints = append(c.AllowFingerprints, other.AllowFingerprints...)
+}
+
+// MergeRepoRules merges the repo rules file, when one exists, on top of
+// cfg and fills in the default policy.
+func MergeRepoRules(cfg Config) (Config, error) {
+	// outside a repository there is no rules file to merge
+	if root, err := git.GetGitRoot(); err == nil {
+		repoCfg, err := loadRulesFile(filepath.Join(root, RulesFileName))
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.merge(repoCfg)
+	}
+
+	if cfg.Policy == "" {
+		cfg.Policy = DefaultPolicy
+	}
+	return cfg, nil
+}
+
+// Validate checks the policy and compiles custom rules so that mistakes are
+// reported when the config is loaded rather than on the first scan.
+func (c Config) Validate() error {
+	switch c.Policy {
+	case "", PolicyWarn, PolicyRedact, PolicyBlock:
+	default:
+		return fmt.Errorf("unknown policy %q (expected warn|redact|block)", c.Policy)
+	}
+
+	for _, rc := range c.Rules {
+		if _, err := rc.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadRulesFile(path string) (Config, error) {
+	var cfg Config
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// compile turns a custom rule definition into a Rule.
+func (rc RuleConfig) compile() (*Rule, error) {
+	if rc.ID == "" {
+		return nil, errors.New("custom rule is missing an id")
+	}
+
+	re, err := regexp.Compile(rc.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: invalid regex: %w", rc.ID, err)
+	}
+	if rc.SecretGroup < 0 || rc.SecretGroup > re.NumSubexp() {
+		return nil, fmt.Errorf("rule %s: secret_group %d out of range", rc.ID, rc.SecretGroup)
+	}
+
+	severity := rc.Severity
+	if severity == "" {
+		severity = SeverityMedium
+	}
+	if severity.Rank() == 0 {
+		return nil, fmt.Errorf("rule %s: unknown severity %q", rc.ID, rc.Severity)
+	}
+
+	switch rc.Category {
+	case "", CategorySecret, CategoryPII:
+	default:
+		return nil, fmt.Errorf("rule %s: unknown category %q", rc.ID, rc.Category)
+	}
+
+	return &Rule{
+		ID:          rc.ID,
+		Description: rc.Description,
+		Category:    rc.Category,
+		Severity:    severity,
+		Regex:       re,
+		SecretGroup: rc.SecretGroup,
+		MinEntropy:  rc.MinEntropy,
+	}, nil
+}
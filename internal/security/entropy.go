@@ -0,0 +1,25 @@
+package security
+
+import "math"
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+// Random tokens score high (base64 ~4.5+, hex ~3.5+), words and identifiers low.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
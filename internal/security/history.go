@@ -0,0 +1,119 @@
+package security
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"huseynovvusal/gitai/internal/git"
+)
+
+// historyStateFile remembers the last commit scanned by ScanHistory,
+// relative to the .git directory.
+const historyStateFile = "gitai/history-scan"
+
+// ScanHistory scans the patches of every commit in revRange (the whole
+// history if empty) with the scanner's rules. A secret is reported once, at
+// the oldest commit that introduced it, with that commit's SHA and author.
+func (s *Scanner) ScanHistory(revRange string) ([]Finding, error) {
+	seen := make(map[string]struct{})
+	var findings []Finding
+
+	err := git.WalkLog(revRange, func(c git.CommitInfo, patch string) error {
+		if strings.TrimSpace(patch) == "" {
+			// merges and empty commits carry no patch
+			return nil
+		}
+
+		commitFindings, err := s.ScanDiff(patch)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range commitFindings {
+			if _, ok := seen[f.Fingerprint]; ok {
+				continue
+			}
+			seen[f.Fingerprint] = struct{}{}
+			f.Commit = c.SHA
+			f.Author = c.Author
+			findings = append(findings, f)
+		}
+		return nil
+	})
+
+	return findings, err
+}
+
+// ScanNewHistory scans the commits after the one recorded by the last clean
+// run, or the whole history when full is set or nothing is recorded. HEAD is
+// recorded only when the scan finds nothing, so a reported secret keeps
+// failing later runs until it is allowlisted or removed from history.
+func (s *Scanner) ScanNewHistory(full bool) ([]Finding, error) {
+	head, err := git.ResolveCommit("HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var revRange string
+	if !full {
+		last, err := LastScannedCommit()
+		if err != nil {
+			return nil, err
+		}
+		if last != "" {
+			revRange = last + "..HEAD"
+		}
+	}
+
+	findings, err := s.ScanHistory(revRange)
+	if err != nil || len(findings) > 0 {
+		return findings, err
+	}
+	return nil, SaveLastScannedCommit(head)
+}
+
+// LastScannedCommit returns the commit recorded by SaveLastScannedCommit, or
+// "" if there is none or it no longer exists (e.g. after a rewrite).
+func LastScannedCommit() (string, error) {
+	path, err := historyStatePath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	sha, err := git.ResolveCommit(strings.TrimSpace(string(data)))
+	if err != nil {
+		return "", nil
+	}
+	return sha, nil
+}
+
+// SaveLastScannedCommit records sha so the next incremental scan starts after it.
+func SaveLastScannedCommit(sha string) error {
+	path, err := historyStatePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(sha+"\n"), 0o644)
+}
+
+func historyStatePath() (string, error) {
+	gitDir, err := git.GetGitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, historyStateFile), nil
+}
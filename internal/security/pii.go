@@ -0,0 +1,178 @@
+package security
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultPIIProviders are the providers PII rules are enforced for when the
+// config does not say otherwise: everything that leaves the machine.
+var DefaultPIIProviders = []string{"gpt", "gemini", "geminicli"}
+
+// piiRules returns the rules detecting personal data rather than credentials.
+func piiRules() []*Rule {
+	return []*Rule{
+		{
+			ID:          "pii_credit_card",
+			Description: "Payment card number (Luhn checked)",
+			Category:    CategoryPII,
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b[2-6](?:[ -]?\d){12,18}\b`),
+			Validate:    isCardNumber,
+		},
+		{
+			ID:          "pii_iban",
+			Description: "International bank account number (checksum verified)",
+			Category:    CategoryPII,
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+			Validate:    isIBAN,
+		},
+		{
+			ID:          "pii_us_ssn",
+			Description: "US social security number",
+			Category:    CategoryPII,
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+			Validate:    isSSN,
+		},
+		{
+			ID:          "pii_uk_nino",
+			Description: "UK national insurance number",
+			Category:    CategoryPII,
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+		},
+		{
+			ID:          "pii_es_dni",
+			Description: "Spanish national identity number (control letter verified)",
+			Category:    CategoryPII,
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b\d{8}[A-HJ-NP-TV-Z]\b`),
+			Validate:    isDNI,
+		},
+		{
+			ID:          "pii_email",
+			Description: "Email address",
+			Category:    CategoryPII,
+			Severity:    SeverityMedium,
+			Regex:       regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+			Validate:    isPersonalEmail,
+		},
+		{
+			ID:          "pii_phone",
+			Description: "Phone number in international or (area) format",
+			Category:    CategoryPII,
+			Severity:    SeverityMedium,
+			Regex:       regexp.MustCompile(`(?:\+[1-9]\d{0,2}[ -]?(?:\(\d{1,4}\)[ -]?)?\d{2,4}(?:[ -]?\d{2,4}){1,4})|(?:\(\d{3}\) ?\d{3}-\d{4})`),
+			Validate:    isPhoneNumber,
+		},
+	}
+}
+
+func digitsOf(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// luhnValid implements the Luhn mod 10 checksum used by payment cards.
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func isCardNumber(s string) bool {
+	d := digitsOf(s)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	// a run of one repeated digit passes Luhn but is a placeholder
+	if strings.Count(d, d[:1]) == len(d) {
+		return false
+	}
+	return luhnValid(d)
+}
+
+// isIBAN verifies the ISO 13616 mod 97 checksum.
+func isIBAN(s string) bool {
+	iban := strings.ReplaceAll(s, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	rearranged := iban[4:] + iban[:4]
+	var b strings.Builder
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			b.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(b.String(), 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// isSSN rejects numbers the SSA never issues (area 000, 666, 9xx; group 00; serial 0000).
+func isSSN(s string) bool {
+	area, group, serial := s[0:3], s[4:6], s[7:11]
+	if area == "000" || area == "666" || area[0] == '9' {
+		return false
+	}
+	return group != "00" && serial != "0000"
+}
+
+func isDNI(s string) bool {
+	const letters = "TRWAGMYFPDXBNJZSQVHLCKE"
+	n := 0
+	for _, r := range s[:8] {
+		n = n*10 + int(r-'0')
+	}
+	return letters[n%23] == s[8]
+}
+
+// isPersonalEmail skips reserved documentation domains and git remotes.
+func isPersonalEmail(s string) bool {
+	local, domain, _ := strings.Cut(strings.ToLower(s), "@")
+	if local == "git" {
+		return false
+	}
+	for _, reserved := range []string{"example.com", "example.org", "example.net", "localhost", "test", "invalid", "example"} {
+		if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
+			return false
+		}
+	}
+	return true
+}
+
+func isPhoneNumber(s string) bool {
+	n := len(digitsOf(s))
+	return n >= 8 && n <= 15
+}
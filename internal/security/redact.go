@@ -0,0 +1,68 @@
+package security
+
+import (
+	"sort"
+	"strings"
+
+	"huseynovvusal/gitai/internal/pathmatch"
+)
+
+// Placeholder returns the text that replaces a secret matched by ruleID.
+func Placeholder(ruleID string) string {
+	return "<REDACTED:" + ruleID + ">"
+}
+
+// Redact replaces every matched secret in the diff with a placeholder.
+// Unlike ScanDiff it also covers context and removed lines, so a secret
+// never leaves the machine just because it was already committed.
+func (s *Scanner) Redact(diffText string) string {
+	lines := strings.SplitAfter(diffText, "\n")
+
+	var oldFile, file string
+	var inHunk, skipFile bool
+	for i, ln := range lines {
+		switch {
+		case strings.HasPrefix(ln, "diff --git "):
+			inHunk = false
+		case !inHunk && strings.HasPrefix(ln, "--- "):
+			oldFile = strings.TrimPrefix(strings.TrimSpace(ln[4:]), "a/")
+		case !inHunk && strings.HasPrefix(ln, "+++ "):
+			file = strings.TrimPrefix(strings.TrimSpace(ln[4:]), "b/")
+			// a deleted file is named by its old path, so allowlists still match
+			if file == "/dev/null" {
+				file = oldFile
+			}
+			skipFile = pathmatch.MatchAny(s.allowPaths, file)
+		case strings.HasPrefix(ln, "@@"):
+			inHunk = true
+		case inHunk && ln != "" && strings.ContainsRune("+- ", rune(ln[0])):
+			if skipFile || strings.Contains(ln, AllowComment) {
+				continue
+			}
+			lines[i] = ln[:1] + s.redactText(file, ln[1:])
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+func (s *Scanner) redactText(file, text string) string {
+	matches := s.matches(file, text)
+	// matches are in rule order; replace right to left so earlier offsets stay valid
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		text = text[:m.start] + Placeholder(m.rule.ID) + text[m.end:]
+	}
+	return text
+}
+
+// RedactDiff redacts diffText using the rules configured for provider.
+func RedactDiff(diffText string, cfg Config, provider string) (string, error) {
+	scanner, err := NewScannerForProvider(cfg, provider)
+	if err != nil {
+		return "", err
+	}
+
+	return scanner.Redact(diffText), nil
+}
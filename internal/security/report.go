@@ -0,0 +1,181 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Report formats accepted by WriteReport.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// WriteReport writes findings to w in the given format. rules is used to
+// describe the rules in SARIF output.
+func WriteReport(w io.Writer, format string, findings []Finding, rules []*Rule) error {
+	switch format {
+	case FormatText, "":
+		return writeText(w, findings)
+	case FormatJSON:
+		return writeJSON(w, findings)
+	case FormatSARIF:
+		return writeSARIF(w, findings, rules)
+	default:
+		return fmt.Errorf("unknown report format: %s", format)
+	}
+}
+
+func writeText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if f.Commit != "" {
+			if _, err := fmt.Fprintf(w, "%s (%s) ", shortSHA(f.Commit), f.Author); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: [%s] %s: %s (fingerprint %s)\n", f.File, f.Line, f.Column, f.Severity, f.RuleID, f.Snippet, f.Fingerprint); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d finding(s)\n", len(findings))
+	return err
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func writeJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		// emit [] rather than null so consumers can always iterate
+		findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(findings)
+}
+
+// Minimal SARIF 2.1.0 types, enough for GitHub code scanning and most CI viewers.
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func writeSARIF(w io.Writer, findings []Finding, rules []*Rule) error {
+	driver := sarifDriver{
+		Name:           "gitai",
+		InformationURI: "https://github.com/huseynovvusal/gitai",
+		Rules:          []sarifRule{},
+	}
+	for _, r := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, f := range findings {
+		msg := fmt.Sprintf("%s: %s", f.RuleID, f.Snippet)
+		if f.Commit != "" {
+			msg += fmt.Sprintf(" (introduced in %s by %s)", f.Commit, f.Author)
+		}
+		results = append(results, sarifResult{
+			RuleID:  f.RuleID,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: msg},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.File},
+					Region:           sarifRegion{StartLine: f.Line, StartColumn: f.Column},
+				},
+			}},
+			PartialFingerprints: map[string]string{"gitai/v1": f.Fingerprint},
+		})
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(log)
+}
@@ -0,0 +1,141 @@
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var reportRules = []*Rule{
+	{ID: "aws_access_key_id", Description: "AWS access key ID", Severity: SeverityCritical},
+	{ID: "pii_email", Description: "Email address", Category: CategoryPII, Severity: SeverityMedium},
+}
+
+var reportFindings = []Finding{
+	{
+		RuleID:      "aws_access_key_id",
+		Category:    CategorySecret,
+		Severity:    SeverityCritical,
+		File:        "config/app.go",
+		Line:        12,
+		Column:      9,
+		Snippet:     `key := "<REDACTED:aws_access_key_id>"`,
+		Fingerprint: "3f1c2a9b8d7e6f50",
+	},
+	{
+		RuleID:      "pii_email",
+		Category:    CategoryPII,
+		Severity:    SeverityMedium,
+		File:        "docs/owners.md",
+		Line:        3,
+		Column:      8,
+		Snippet:     "owner: <REDACTED:pii_email>",
+		Fingerprint: "a0b1c2d3e4f50617",
+		Commit:      "0123456789abcdef0123456789abcdef01234567",
+		Author:      "Jane Doe <jane@example.com>",
+	},
+}
+
+// TestWriteReport compares each report format with testdata/report/findings.<format>.
+func TestWriteReport(t *testing.T) {
+	for _, format := range []string{FormatText, FormatJSON, FormatSARIF} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteReport(&buf, format, reportFindings, reportRules); err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join("testdata", "report", "findings."+format))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("WriteReport(%s) =\n%s\n--- want ---\n%s", format, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestWriteReport_SARIFStructure(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, FormatSARIF, reportFindings, reportRules); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF log = version %q with %d runs", log.Version, len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(reportRules) {
+		t.Errorf("driver has %d rules, want %d", len(run.Tool.Driver.Rules), len(reportRules))
+	}
+	if len(run.Results) != len(reportFindings) {
+		t.Fatalf("%d results, want %d", len(run.Results), len(reportFindings))
+	}
+	for i, r := range run.Results {
+		f := reportFindings[i]
+		if r.RuleID != f.RuleID {
+			t.Errorf("result %d: ruleId %q, want %q", i, r.RuleID, f.RuleID)
+		}
+		if len(r.Locations) != 1 {
+			t.Fatalf("result %d: %d locations, want 1", i, len(r.Locations))
+		}
+		loc := r.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URI != f.File || loc.Region.StartLine != f.Line || loc.Region.StartColumn != f.Column {
+			t.Errorf("result %d: location %+v, want %s:%d:%d", i, loc, f.File, f.Line, f.Column)
+		}
+		if r.PartialFingerprints["gitai/v1"] != f.Fingerprint {
+			t.Errorf("result %d: fingerprints %v, want gitai/v1=%s", i, r.PartialFingerprints, f.Fingerprint)
+		}
+	}
+	if run.Results[0].Level != "error" || run.Results[1].Level != "warning" {
+		t.Errorf("levels = %s, %s; want error, warning", run.Results[0].Level, run.Results[1].Level)
+	}
+}
+
+func TestWriteReport_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, FormatJSON, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty JSON report = %q, want []", buf.String())
+	}
+	if err := WriteReport(&buf, "xml", nil, nil); err == nil {
+		t.Error("WriteReport accepted an unknown format")
+	}
+}
@@ -0,0 +1,251 @@
+package security
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Category groups rules so that whole classes can be switched on per provider.
+type Category string
+
+const (
+	CategorySecret Category = "secret"
+	CategoryPII    Category = "pii"
+)
+
+// Rank orders severities from low (1) to critical (4); unknown values rank 0.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Rule is a named detector applied to every added line of a diff.
+type Rule struct {
+	ID          string
+	Description string
+	// Category defaults to CategorySecret when empty.
+	Category Category
+	Severity Severity
+	Regex    *regexp.Regexp
+	// SecretGroup is the capture group holding the secret value; 0 means the whole match.
+	SecretGroup int
+	// MinEntropy, when non-zero, is the minimum Shannon entropy (bits/char)
+	// the secret must have, which filters out placeholders and identifiers.
+	MinEntropy float64
+	// Validate, when set, is an extra check on the secret value.
+	Validate func(secret string) bool
+}
+
+func (r *Rule) category() Category {
+	if r.Category == "" {
+		return CategorySecret
+	}
+	return r.Category
+}
+
+// ruleMatch is a single hit of a rule on a line.
+type ruleMatch struct {
+	rule   *Rule
+	start  int // byte offset of the secret in the line
+	end    int
+	secret string
+}
+
+// find returns every match of the rule on line that passes the entropy and
+// validation checks.
+func (r *Rule) find(line string) []ruleMatch {
+	var out []ruleMatch
+	for _, loc := range r.Regex.FindAllStringSubmatchIndex(line, -1) {
+		g := r.SecretGroup
+		if 2*g+1 >= len(loc) || loc[2*g] < 0 {
+			continue
+		}
+		start, end := loc[2*g], loc[2*g+1]
+		secret := line[start:end]
+
+		if r.MinEntropy > 0 && shannonEntropy(secret) < r.MinEntropy {
+			continue
+		}
+		if r.Validate != nil && !r.Validate(secret) {
+			continue
+		}
+		out = append(out, ruleMatch{rule: r, start: start, end: end, secret: secret})
+	}
+	return out
+}
+
+// DefaultRules returns the built-in rule set. Specific rules come first so
+// that they win over the generic ones when both match the same value.
+func DefaultRules() []*Rule {
+	return defaultRules(SensitiveKeywords)
+}
+
+// defaultRules builds the built-in rule set with the given keywords feeding
+// the generic assignment rules.
+func defaultRules(keywords []string) []*Rule {
+	return []*Rule{
+		{
+			ID:          "aws_access_key_id",
+			Description: "AWS access key ID",
+			Severity:    SeverityCritical,
+			Regex:       regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16})\b`),
+			SecretGroup: 1,
+		},
+		{
+			ID:          "aws_secret_access_key",
+			Description: "AWS secret access key",
+			Severity:    SeverityCritical,
+			Regex:       regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*(?:=|:=|:|=>)\s*["']?([A-Za-z0-9/+=]{40})\b`),
+			SecretGroup: 1,
+			MinEntropy:  3.5,
+		},
+		{
+			ID:          "gcp_api_key",
+			Description: "Google Cloud API key",
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})\b`),
+			SecretGroup: 1,
+		},
+		{
+			ID:          "gcp_service_account",
+			Description: "Google Cloud service account key file",
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`"type"\s*:\s*"service_account"`),
+		},
+		{
+			ID:          "github_token",
+			Description: "GitHub personal access, OAuth, app or refresh token",
+			Severity:    SeverityCritical,
+			Regex:       regexp.MustCompile(`\b((?:gh[pousr]_[A-Za-z0-9]{36,255})|(?:github_pat_[A-Za-z0-9_]{82}))\b`),
+			SecretGroup: 1,
+		},
+		{
+			ID:          "slack_token",
+			Description: "Slack bot, user or app token",
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b(xox[abposr]-[0-9A-Za-z-]{10,})`),
+			SecretGroup: 1,
+		},
+		{
+			ID:          "slack_webhook",
+			Description: "Slack incoming webhook URL",
+			Severity:    SeverityMedium,
+			Regex:       regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+`),
+		},
+		{
+			ID:          "jwt",
+			Description: "JSON Web Token",
+			Severity:    SeverityHigh,
+			Regex:       regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`),
+			SecretGroup: 1,
+		},
+		{
+			ID:          "private_key",
+			Description: "PEM encoded private key",
+			Severity:    SeverityCritical,
+			Regex:       regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`),
+		},
+		{
+			ID:          "generic_secret_assignment",
+			Description: "Quoted value assigned to a secret-looking name",
+			Severity:    SeverityMedium,
+			Regex:       regexp.MustCompile(`(?i)[\w.-]*(?:` + keywordPattern(keywords) + `)[\w.-]*["']?\s*(?:=|:=|:|=>)\s*["']([^"'\s]{8,})["']`),
+			SecretGroup: 1,
+			MinEntropy:  3.0,
+			Validate:    isLiteralValue,
+		},
+		{
+			ID:          "generic_secret_env",
+			Description: "Dotenv style secret assignment",
+			Severity:    SeverityMedium,
+			Regex:       regexp.MustCompile(`(?i)^\s*(?:export\s+)?[\w.-]*(?:` + keywordPattern(keywords) + `)[\w.-]*\s*=\s*([^\s"'#]{8,})\s*$`),
+			SecretGroup: 1,
+			MinEntropy:  3.0,
+			Validate:    isLiteralValue,
+		},
+		{
+			ID:          "generic_high_entropy",
+			Description: "High entropy string that looks like a token",
+			Severity:    SeverityLow,
+			Regex:       regexp.MustCompile(`[A-Za-z0-9+/_\-]{32,}={0,2}`),
+			MinEntropy:  4.5,
+			Validate:    hasLettersAndDigits,
+		},
+	}
+}
+
+// keywordPattern builds a regexp alternation from keywords, used by the
+// generic rules to recognise secret-looking variable names.
+func keywordPattern(keywords []string) string {
+	if len(keywords) == 0 {
+		// an empty alternation would match every assignment; match nothing instead
+		return `[^\x00-\x{10FFFF}]`
+	}
+	parts := make([]string, 0, len(keywords))
+	for _, kw := range keywords {
+		parts = append(parts, regexp.QuoteMeta(kw))
+	}
+	return strings.Join(parts, "|")
+}
+
+// isLiteralValue rejects values that reference another variable or template
+// placeholder instead of containing the secret itself.
+func isLiteralValue(s string) bool {
+	return !strings.HasPrefix(s, "$") && !strings.HasPrefix(s, "{{") && !strings.HasPrefix(s, "%")
+}
+
+func hasLettersAndDigits(s string) bool {
+	var letter, digit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return letter && digit
+}
+
+// scanLine applies rules to a single line. When several rules match the same
+// span only the first one (in rule order) is reported.
+func scanLine(rules []*Rule, line string) []ruleMatch {
+	var out []ruleMatch
+	for _, r := range rules {
+		for _, m := range r.find(line) {
+			if !overlaps(out, m) {
+				out = append(out, m)
+			}
+		}
+	}
+	return out
+}
+
+func overlaps(existing []ruleMatch, m ruleMatch) bool {
+	for _, e := range existing {
+		if m.start < e.end && e.start < m.end {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package security
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/url"
 	"os"
@@ -8,14 +10,33 @@ import (
 	"strings"
 
 	"github.com/sourcegraph/go-diff/diff"
+
+	"huseynovvusal/gitai/internal/pathmatch"
 )
 
+// Finding is a single rule hit in an added line. Snippet is the trimmed line
+// with every detected secret replaced by its placeholder, so findings can be
+// printed or exported without leaking the values they report.
 type Finding struct {
-	File string
-	Line int
-	Text string
+	RuleID      string   `json:"rule_id"`
+	Category    Category `json:"category"`
+	Severity    Severity `json:"severity"`
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Column      int      `json:"column"`
+	Snippet     string   `json:"snippet"`
+	Fingerprint string   `json:"fingerprint"`
+	// Commit and Author are only set by ScanHistory.
+	Commit string `json:"commit,omitempty"`
+	Author string `json:"author,omitempty"`
 }
 
+// AllowComment marks a line as a known false positive, e.g.
+// `const example = "AKIA..." // gitai:allow`.
+const AllowComment = "gitai:allow"
+
+// SensitiveKeywords are the variable-name fragments the generic rules use to
+// recognise secret assignments such as `db_password = "..."`.
 var SensitiveKeywords = []string{
 	"password",
 	"passwd",
@@ -62,10 +83,96 @@ func parseKeywordsCSV(csv string) []string {
 	return out
 }
 
-func CheckDiffSafety(diffText string) error {
+// DefaultAllowPaths are never scanned: lock files are full of integrity
+// hashes that look like high entropy tokens, which floods history scans.
+var DefaultAllowPaths = []string{
+	"go.sum",
+	"package-lock.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"Cargo.lock",
+	"poetry.lock",
+}
+
+// Scanner applies a rule set and the configured allowlists to diffs.
+type Scanner struct {
+	rules             []*Rule
+	allowPaths        []string
+	allowFingerprints map[string]struct{}
+}
+
+// NewScanner builds a scanner from the built-in secret rules and cfg. Custom
+// rules take precedence over built-in rules with the same ID.
+func NewScanner(cfg Config) (*Scanner, error) {
+	return newScanner(cfg, false)
+}
+
+// NewScannerForProvider is like NewScanner but also enables the PII rules
+// when cfg enforces them for provider.
+func NewScannerForProvider(cfg Config, provider string) (*Scanner, error) {
+	return newScanner(cfg, cfg.PIIEnforcedFor(provider))
+}
+
+// NewPIIScanner builds a scanner with both secret and PII rules enabled.
+func NewPIIScanner(cfg Config) (*Scanner, error) {
+	return newScanner(cfg, true)
+}
+
+func newScanner(cfg Config, includePII bool) (*Scanner, error) {
+	disabled := make(map[string]struct{}, len(cfg.DisabledRules))
+	for _, id := range cfg.DisabledRules {
+		disabled[id] = struct{}{}
+	}
+
+	custom := make(map[string]struct{}, len(cfg.Rules))
+	var rules []*Rule
+	for _, rc := range cfg.Rules {
+		r, err := rc.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid security config: %w", err)
+		}
+		custom[r.ID] = struct{}{}
+		// custom PII rules follow the same per-provider gating as built-in ones
+		if r.category() == CategoryPII && !includePII {
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	keywords := append(append([]string{}, SensitiveKeywords...), parseKeywordsCSV(strings.Join(cfg.Keywords, ","))...)
+	builtin := defaultRules(keywords)
+	if includePII {
+		builtin = append(builtin, piiRules()...)
+	}
+	for _, r := range builtin {
+		if _, ok := custom[r.ID]; !ok {
+			rules = append(rules, r)
+		}
+	}
+
+	enabled := rules[:0]
+	for _, r := range rules {
+		if _, ok := disabled[r.ID]; !ok {
+			enabled = append(enabled, r)
+		}
+	}
+
+	fingerprints := make(map[string]struct{}, len(cfg.AllowFingerprints))
+	for _, fp := range cfg.AllowFingerprints {
+		fingerprints[strings.TrimSpace(fp)] = struct{}{}
+	}
+
+	allowPaths := append(append([]string{}, DefaultAllowPaths...), cfg.AllowPaths...)
+
+	return &Scanner{rules: enabled, allowPaths: allowPaths, allowFingerprints: fingerprints}, nil
+}
+
+// ScanDiff returns the findings in the added lines of a unified diff,
+// excluding allowlisted paths, fingerprints and lines marked with AllowComment.
+func (s *Scanner) ScanDiff(diffText string) ([]Finding, error) {
 	fileDiffs, err := diff.ParseMultiFileDiff([]byte(diffText))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var findings []Finding
@@ -73,6 +180,10 @@ func CheckDiffSafety(diffText string) error {
 		filename := strings.TrimPrefix(fd.NewName, "b/")
 		filename = strings.TrimPrefix(filename, "a/")
 
+		if pathmatch.MatchAny(s.allowPaths, filename) {
+			continue
+		}
+
 		for _, h := range fd.Hunks {
 			lines := strings.Split(string(h.Body), "\n")
 			newLine := int(h.NewStartLine)
@@ -88,8 +199,8 @@ func CheckDiffSafety(diffText string) error {
 						continue
 					}
 					text := strings.TrimPrefix(ln, "+")
-					if containsKeyword(text) {
-						findings = append(findings, Finding{File: filename, Line: newLine, Text: strings.TrimSpace(text)})
+					if !strings.Contains(text, AllowComment) {
+						findings = append(findings, s.scanText(filename, newLine, text)...)
 					}
 					newLine++
 				case ' ':
@@ -104,10 +215,69 @@ func CheckDiffSafety(diffText string) error {
 		}
 	}
 
-	if len(findings) == 0 {
+	return findings, nil
+}
+
+// matches returns the rule matches on text that are not allowlisted by fingerprint.
+func (s *Scanner) matches(file string, text string) []ruleMatch {
+	var out []ruleMatch
+	for _, m := range scanLine(s.rules, text) {
+		if _, ok := s.allowFingerprints[fingerprint(file, m.rule.ID, m.secret)]; !ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (s *Scanner) scanText(file string, line int, text string) []Finding {
+	matches := s.matches(file, text)
+	if len(matches) == 0 {
 		return nil
 	}
 
+	snippet := strings.TrimSpace(s.redactText(file, text))
+	var findings []Finding
+	for _, m := range matches {
+		findings = append(findings, Finding{
+			File:        file,
+			Line:        line,
+			Column:      m.start + 1,
+			Snippet:     snippet,
+			RuleID:      m.rule.ID,
+			Category:    m.rule.category(),
+			Severity:    m.rule.Severity,
+			Fingerprint: fingerprint(file, m.rule.ID, m.secret),
+		})
+	}
+	return findings
+}
+
+// fingerprint identifies a finding independently of its line number, so it
+// stays stable while the surrounding code changes.
+func fingerprint(file, ruleID, secret string) string {
+	sum := sha256.Sum256([]byte(file + "\x00" + ruleID + "\x00" + secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Rules returns the enabled rules in evaluation order.
+func (s *Scanner) Rules() []*Rule {
+	return s.rules
+}
+
+// CheckDiffSafety scans the diff with the rules configured for provider and
+// returns the findings; an empty result means the diff is safe to send.
+func CheckDiffSafety(diffText string, cfg Config, provider string) ([]Finding, error) {
+	scanner, err := NewScannerForProvider(cfg, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanner.ScanDiff(diffText)
+}
+
+// FormatFindings renders findings one per line with file:// links, for
+// display in the terminal.
+func FormatFindings(findings []Finding) string {
 	var b strings.Builder
 	cwd, _ := os.Getwd()
 	for _, f := range findings {
@@ -118,18 +288,7 @@ func CheckDiffSafety(diffText string) error {
 		// create file:// URI with encoded path so terminals like VS Code treat it as a clickable link
 		u := url.URL{Scheme: "file", Path: abs}
 		fileURI := u.String()
-		b.WriteString(fmt.Sprintf("- %s:%d:1: %s\n", fileURI, f.Line, f.Text))
-	}
-
-	return fmt.Errorf("%s", b.String())
-}
-
-func containsKeyword(s string) bool {
-	ls := strings.ToLower(s)
-	for _, kw := range SensitiveKeywords {
-		if strings.Contains(ls, kw) {
-			return true
-		}
+		b.WriteString(fmt.Sprintf("- %s:%d:%d: [%s] %s: %s (fingerprint %s)\n", fileURI, f.Line, f.Column, f.Severity, f.RuleID, f.Snippet, f.Fingerprint))
 	}
-	return false
+	return b.String()
 }
@@ -0,0 +1,170 @@
+// Package trailer adds issue keys and git trailers (Refs, Co-authored-by,
+// Signed-off-by) to commit messages.
+package trailer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/lint"
+)
+
+// Policy is where the ticket key of the branch goes in the message.
+type Policy string
+
+const (
+	// PolicyNone only shows the ticket to prompt templates.
+	PolicyNone Policy = "none"
+	// PolicyPrefix puts the ticket in front of the subject.
+	PolicyPrefix Policy = "prefix"
+	// PolicyTrailer adds a trailer such as "Refs: ABC-123".
+	PolicyTrailer Policy = "trailer"
+)
+
+// TicketConfig is the `ticket` section of the gitai config.
+type TicketConfig struct {
+	// Pattern finds the ticket in the branch name. The key is the first
+	// capture group if the pattern has one, otherwise the whole match.
+	Pattern string `mapstructure:"pattern"`
+	// Format turns the key into the ticket reference; "{key}" is replaced,
+	// e.g. "#{key}" for GitHub issues.
+	Format string `mapstructure:"format"`
+	Policy Policy `mapstructure:"policy"`
+	// Trailer is the trailer key used by PolicyTrailer.
+	Trailer string `mapstructure:"trailer"`
+}
+
+// Config is the `trailers` section of the gitai config.
+type Config struct {
+	// CoAuthors are "Name <email>" pairs offered as Co-authored-by trailers.
+	CoAuthors []string `mapstructure:"co_authors"`
+	// SignOff adds a Signed-off-by trailer with the committer identity.
+	SignOff bool `mapstructure:"sign_off"`
+}
+
+// Defaults used by the config layer.
+const (
+	DefaultTicketPattern = `[A-Z][A-Z0-9]+-[0-9]+`
+	DefaultTicketFormat  = "{key}"
+	DefaultTicketTrailer = "Refs"
+)
+
+var (
+	trailerKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+	identPattern      = regexp.MustCompile(`^[^<>]+ <[^<>\s]+>$`)
+)
+
+func (c TicketConfig) Validate() error {
+	if _, err := regexp.Compile(c.Pattern); err != nil {
+		return fmt.Errorf("pattern: %w", err)
+	}
+	switch c.Policy {
+	case "", PolicyNone, PolicyPrefix, PolicyTrailer:
+	default:
+		return fmt.Errorf("unknown policy %q (expected none|prefix|trailer)", c.Policy)
+	}
+	if c.Policy == PolicyTrailer && !trailerKeyPattern.MatchString(c.Trailer) {
+		return fmt.Errorf("trailer %q is not a valid trailer key", c.Trailer)
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	for _, a := range c.CoAuthors {
+		if !identPattern.MatchString(strings.TrimSpace(a)) {
+			return fmt.Errorf("co_authors: %q should look like \"Name <email>\"", a)
+		}
+	}
+	return nil
+}
+
+// Ticket returns the ticket reference in branch, or "" if there is none.
+func Ticket(cfg TicketConfig, branch string) string {
+	if cfg.Pattern == "" || branch == "" {
+		return ""
+	}
+	re, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return ""
+	}
+	m := re.FindStringSubmatch(branch)
+	if m == nil {
+		return ""
+	}
+	key := m[0]
+	if len(m) > 1 {
+		key = m[1]
+	}
+	if key == "" {
+		return ""
+	}
+
+	format := cfg.Format
+	if format == "" {
+		format = DefaultTicketFormat
+	}
+	return strings.ReplaceAll(format, "{key}", key)
+}
+
+// AddTicket places ticket in msg according to cfg.Policy. A message that
+// already mentions the ticket is returned unchanged.
+func AddTicket(cfg TicketConfig, msg, ticket string) string {
+	if ticket == "" || strings.Contains(msg, ticket) {
+		return msg
+	}
+
+	switch cfg.Policy {
+	case PolicyPrefix:
+		subject, rest, hasBody := strings.Cut(msg, "\n")
+		if h, ok := lint.ParseHeader(subject); ok {
+			// keep the Conventional Commits header parseable
+			subject = subject[:len(subject)-len(h.Subject)] + ticket + " " + h.Subject
+		} else {
+			subject = ticket + " " + subject
+		}
+		if hasBody {
+			return subject + "\n" + rest
+		}
+		return subject
+	case PolicyTrailer:
+		key := cfg.Trailer
+		if key == "" {
+			key = DefaultTicketTrailer
+		}
+		return Add(msg, key, ticket)
+	default:
+		return msg
+	}
+}
+
+// Add appends the trailer "key: value" to msg, joining an existing trailer
+// block at the end of the message. A trailer that is already present is not
+// added twice.
+func Add(msg, key, value string) string {
+	line := key + ": " + value
+	msg = strings.TrimRight(msg, "\n")
+
+	paragraphs := strings.Split(msg, "\n\n")
+	last := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	for _, l := range last {
+		if strings.EqualFold(l, line) {
+			return msg
+		}
+	}
+
+	if len(paragraphs) > 1 && isTrailerBlock(last) {
+		return msg + "\n" + line
+	}
+	return msg + "\n\n" + line
+}
+
+func isTrailerBlock(lines []string) bool {
+	for _, l := range lines {
+		if !ai.IsTrailer(l) {
+			return false
+		}
+	}
+	return true
+}
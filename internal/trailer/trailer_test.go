@@ -0,0 +1,88 @@
+package trailer
+
+import "testing"
+
+func TestTicket(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    TicketConfig
+		branch string
+		want   string
+	}{
+		{"default pattern", TicketConfig{Pattern: DefaultTicketPattern}, "feature/ABC-123-login", "ABC-123"},
+		{"digits in the project", TicketConfig{Pattern: DefaultTicketPattern}, "PROJ2-7", "PROJ2-7"},
+		{"lowercase is no key", TicketConfig{Pattern: DefaultTicketPattern}, "fix/abc-123", ""},
+		{"no key", TicketConfig{Pattern: DefaultTicketPattern}, "main", ""},
+		{"capture group and format", TicketConfig{Pattern: `^(?:\w+/)?(\d+)-`, Format: "#{key}"}, "fix/456-crash", "#456"},
+		{"no pattern", TicketConfig{}, "ABC-1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Ticket(tt.cfg, tt.branch); got != tt.want {
+				t.Errorf("Ticket(%q) = %q, want %q", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTicket(t *testing.T) {
+	prefix := TicketConfig{Policy: PolicyPrefix}
+	refs := TicketConfig{Policy: PolicyTrailer, Trailer: "Refs"}
+
+	tests := []struct {
+		name string
+		cfg  TicketConfig
+		msg  string
+		want string
+	}{
+		{"prefix conventional", prefix, "feat(auth): add login\n\nbody", "feat(auth): ABC-1 add login\n\nbody"},
+		{"prefix plain", prefix, "Add login", "ABC-1 Add login"},
+		{"trailer", refs, "feat: add login", "feat: add login\n\nRefs: ABC-1"},
+		{"already mentioned", refs, "feat: add login for ABC-1", "feat: add login for ABC-1"},
+		{"policy none", TicketConfig{Policy: PolicyNone}, "feat: add login", "feat: add login"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AddTicket(tt.cfg, tt.msg, "ABC-1"); got != tt.want {
+				t.Errorf("AddTicket() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	const signed = "Signed-off-by: Jane Doe <jane@example.com>"
+	tests := []struct {
+		name, msg, want string
+	}{
+		{"subject only", "fix: x", "fix: x\n\n" + signed},
+		{"after a body", "fix: x\n\nSome body text.\n", "fix: x\n\nSome body text.\n\n" + signed},
+		{"joins trailers", "fix: x\n\nRefs: ABC-1", "fix: x\n\nRefs: ABC-1\n" + signed},
+		{"not twice", "fix: x\n\n" + signed, "fix: x\n\n" + signed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Add(tt.msg, "Signed-off-by", "Jane Doe <jane@example.com>"); got != tt.want {
+				t.Errorf("Add() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (TicketConfig{Pattern: "(", Policy: PolicyNone}).Validate(); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+	if err := (TicketConfig{Pattern: DefaultTicketPattern, Policy: "suffix"}).Validate(); err == nil {
+		t.Error("unknown policy accepted")
+	}
+	if err := (TicketConfig{Pattern: DefaultTicketPattern, Policy: PolicyTrailer, Trailer: "Refs to"}).Validate(); err == nil {
+		t.Error("invalid trailer key accepted")
+	}
+	if err := (Config{CoAuthors: []string{"Jane Doe <jane@example.com>"}}).Validate(); err != nil {
+		t.Errorf("valid co-author rejected: %v", err)
+	}
+	if err := (Config{CoAuthors: []string{"jane@example.com"}}).Validate(); err == nil {
+		t.Error("co-author without a name accepted")
+	}
+}
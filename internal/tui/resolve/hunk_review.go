@@ -0,0 +1,384 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/conflict"
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/tui/suggest/shared"
+	"huseynovvusal/gitai/internal/usage"
+)
+
+type proposalMsg struct {
+	resolution ai.ConflictResolution
+}
+
+type proposalErrorMsg struct {
+	err error
+}
+
+type editDoneMsg struct {
+	text string
+	err  error
+}
+
+type fileWrittenMsg struct {
+	summary string
+	err     error
+}
+
+type State int
+
+const (
+	StateProposing State = iota // waiting for the AI proposal for the current hunk
+	StateReviewing              // proposal shown; accept, edit, or reject
+	StateWriting                // writing and staging the current file
+	StateDone                   // all files processed
+	StateError                  // unrecoverable error (store message)
+)
+
+// conflictedFile is a file with conflict markers queued for review.
+type conflictedFile struct {
+	path    string // absolute path used for reading/writing
+	display string // repo-relative path shown to the user and the model
+	file    *conflict.File
+	mode    os.FileMode
+}
+
+type HunkReviewModel struct {
+	files    []conflictedFile
+	fileIdx  int
+	hunkIdx  int
+	proposal *ai.ConflictResolution
+	state    State
+	spinner  spinner.Model
+	errMsg   string
+	summary  []string
+	width    int
+	aiConfig ai.Config
+	usage    usage.Config
+	editor   string
+	ctx      context.Context
+	quitting bool // quit once the file being written is done
+}
+
+var paneStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(shared.FileFg).
+	Padding(0, 1)
+
+func NewHunkReviewModel(ctx context.Context, files []conflictedFile, aiConfig ai.Config, usageCfg usage.Config, editor string) HunkReviewModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = shared.CursorStyle
+
+	return HunkReviewModel{
+		files:    files,
+		state:    StateProposing,
+		spinner:  s,
+		width:    120,
+		aiConfig: aiConfig,
+		usage:    usageCfg,
+		editor:   editor,
+		ctx:      ctx,
+	}
+}
+
+func (m *HunkReviewModel) currentFile() *conflictedFile {
+	return &m.files[m.fileIdx]
+}
+
+func (m *HunkReviewModel) currentHunk() *conflict.Hunk {
+	return m.currentFile().file.Hunks()[m.hunkIdx]
+}
+
+func runProposalAsync(ctx context.Context, cfg ai.Config, usageCfg usage.Config, path string, hunk *conflict.Hunk) tea.Cmd {
+	return func() tea.Msg {
+		res, err := ai.ResolveConflict(ctx, cfg, path, hunk)
+		if err != nil {
+			return proposalErrorMsg{err: err}
+		}
+		usage.Track(usageCfg, usage.OpResolve, res.Call)
+		return proposalMsg{resolution: res}
+	}
+}
+
+// runEditor opens text in editor (tui.editor), falling back to $VISUAL and
+// $EDITOR, and reports the edited result.
+func runEditor(editor, text string) tea.Cmd {
+	tmp, err := os.CreateTemp("", "gitai-resolve-*")
+	if err != nil {
+		return func() tea.Msg { return editDoneMsg{err: err} }
+	}
+	_, err = tmp.WriteString(text)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return func() tea.Msg { return editDoneMsg{err: err} }
+	}
+
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// the editor may carry arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), tmp.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(tmp.Name())
+		if err != nil {
+			return editDoneMsg{err: err}
+		}
+		out, err := os.ReadFile(tmp.Name())
+		return editDoneMsg{text: string(out), err: err}
+	})
+}
+
+// runWriteAsync writes the rendered file and stages it once every hunk has
+// been resolved. Files with rejected hunks keep their markers and stay unstaged.
+func runWriteAsync(f conflictedFile) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.WriteFile(f.path, []byte(f.file.Render()), f.mode); err != nil {
+			return fileWrittenMsg{err: err}
+		}
+
+		if n := f.file.Unresolved(); n > 0 {
+			return fileWrittenMsg{summary: fmt.Sprintf("%s: %d hunk(s) left unresolved, not staged", f.display, n)}
+		}
+
+		if err := git.Stage([]string{f.path}); err != nil {
+			return fileWrittenMsg{err: err}
+		}
+		return fileWrittenMsg{summary: fmt.Sprintf("%s: resolved and staged", f.display)}
+	}
+}
+
+func (m *HunkReviewModel) requestProposal() tea.Cmd {
+	m.state = StateProposing
+	m.proposal = nil
+	m.errMsg = ""
+	return tea.Batch(m.spinner.Tick, runProposalAsync(m.ctx, m.aiConfig, m.usage, m.currentFile().display, m.currentHunk()))
+}
+
+// advance moves to the next hunk, writing the current file when its last
+// hunk has been decided.
+func (m *HunkReviewModel) advance() tea.Cmd {
+	m.hunkIdx++
+	if m.hunkIdx < len(m.currentFile().file.Hunks()) {
+		return m.requestProposal()
+	}
+
+	m.state = StateWriting
+	return runWriteAsync(*m.currentFile())
+}
+
+// quit ends the review without losing decided hunks: the current file is
+// written with its undecided hunks keeping their markers, which leaves it
+// unstaged, before the program exits.
+func (m *HunkReviewModel) quit() tea.Cmd {
+	switch m.state {
+	case StateWriting:
+		m.quitting = true
+		return nil
+	case StateProposing, StateReviewing:
+		f := m.currentFile()
+		if f.file.Unresolved() == len(f.file.Hunks()) {
+			// nothing decided yet; the file on disk is still the original
+			return tea.Quit
+		}
+		m.quitting = true
+		m.state = StateWriting
+		return runWriteAsync(*f)
+	default:
+		return tea.Quit
+	}
+}
+
+func (m *HunkReviewModel) Init() tea.Cmd {
+	if len(m.files) == 0 {
+		m.state = StateDone
+		return tea.Quit
+	}
+	return m.requestProposal()
+}
+
+func (m *HunkReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, m.quit()
+		}
+
+		if m.state != StateReviewing {
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "a", "enter":
+			if m.proposal != nil {
+				m.currentHunk().Resolve(m.proposal.Resolution)
+				return m, m.advance()
+			}
+		case "o":
+			m.currentHunk().Resolve(m.currentHunk().Ours)
+			return m, m.advance()
+		case "t":
+			m.currentHunk().Resolve(m.currentHunk().Theirs)
+			return m, m.advance()
+		case "e":
+			text := m.currentHunk().Ours
+			if m.proposal != nil {
+				text = m.proposal.Resolution
+			}
+			return m, runEditor(m.editor, text)
+		case "r":
+			m.currentHunk().Reset()
+			return m, m.advance()
+		case "g":
+			return m, m.requestProposal()
+		}
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case proposalMsg:
+		if m.quitting {
+			return m, nil
+		}
+		m.proposal = &msg.resolution
+		m.state = StateReviewing
+		return m, nil
+
+	case proposalErrorMsg:
+		if m.quitting {
+			return m, nil
+		}
+		// the user can still pick a side or edit by hand
+		m.state = StateReviewing
+		m.errMsg = msg.err.Error()
+		return m, nil
+
+	case editDoneMsg:
+		if msg.err != nil {
+			m.errMsg = msg.err.Error()
+			return m, nil
+		}
+		m.currentHunk().Resolve(msg.text)
+		return m, m.advance()
+
+	case fileWrittenMsg:
+		if msg.err != nil {
+			m.state = StateError
+			m.errMsg = msg.err.Error()
+			return m, tea.Quit
+		}
+		m.summary = append(m.summary, msg.summary)
+
+		m.fileIdx++
+		m.hunkIdx = 0
+		if m.quitting || m.fileIdx >= len(m.files) {
+			m.state = StateDone
+			return m, tea.Quit
+		}
+		return m, m.requestProposal()
+	}
+
+	return m, nil
+}
+
+func (m *HunkReviewModel) View() string {
+	switch m.state {
+	case StateProposing:
+		f := m.currentFile()
+		return "\n" + shared.HeaderStyle.Render(fmt.Sprintf("Resolving %s (hunk %d/%d)", f.display, m.hunkIdx+1, len(f.file.Hunks()))) +
+			"\n\n" + m.spinner.View() + " Asking AI for a resolution..." + "\n"
+
+	case StateWriting:
+		return "\n" + shared.HeaderStyle.Render("Writing "+m.currentFile().display+"...") + "\n"
+
+	case StateDone:
+		var b strings.Builder
+		b.WriteString("\n" + shared.HeaderStyle.Render("Conflict resolution finished:") + "\n")
+		for _, s := range m.summary {
+			b.WriteString(" - " + shared.FileStyle.Render(s) + "\n")
+		}
+		return b.String()
+
+	case StateError:
+		var b strings.Builder
+		b.WriteString("\n" + shared.HeaderStyle.Render("Resolve failed:") + "\n")
+		b.WriteString(shared.ErrorStyle.Render(m.errMsg) + "\n")
+		return b.String()
+
+	case StateReviewing:
+		return m.reviewView()
+
+	default:
+		return "\n" + shared.HeaderStyle.Render("Unknown state") + "\n"
+	}
+}
+
+func (m *HunkReviewModel) reviewView() string {
+	f := m.currentFile()
+	h := m.currentHunk()
+
+	var b strings.Builder
+	header := fmt.Sprintf("Resolving %s (hunk %d/%d, line %d)", f.display, m.hunkIdx+1, len(f.file.Hunks()), h.StartLine)
+	b.WriteString("\n" + shared.HeaderStyle.Render(header) + "\n")
+
+	proposed := ""
+	if m.proposal != nil {
+		proposed = m.proposal.Resolution
+		if m.proposal.Rationale != "" {
+			b.WriteString(m.proposal.Rationale + "\n")
+		}
+	}
+	if m.errMsg != "" {
+		b.WriteString(shared.ErrorStyle.Render(m.errMsg) + "\n")
+	}
+
+	// three panes side by side, accounting for border and padding
+	paneWidth := m.width/3 - 4
+	if paneWidth < 20 {
+		paneWidth = 20
+	}
+	pane := func(title, body string) string {
+		return paneStyle.Width(paneWidth).Render(shared.CheckedStyle.Render(title) + "\n" + strings.TrimRight(body, "\n"))
+	}
+
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+		pane("Ours "+h.OursLabel, h.Ours),
+		pane("Theirs "+h.TheirsLabel, h.Theirs),
+		pane("Proposed", proposed),
+	) + "\n")
+
+	if m.proposal != nil {
+		b.WriteString("\n[a] Accept   [e] Edit   [r] Reject   [o] Ours   [t] Theirs   [q] Quit\n")
+	} else {
+		b.WriteString("\n[g] Retry   [e] Edit   [r] Reject   [o] Ours   [t] Theirs   [q] Quit\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package resolve
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/conflict"
+	"huseynovvusal/gitai/internal/usage"
+)
+
+func TestQuit_KeepsDecidedHunks(t *testing.T) {
+	const second = "<<<<<<< HEAD\nours 2\n=======\ntheirs 2\n>>>>>>> feature\n"
+	content := "<<<<<<< HEAD\nours 1\n=======\ntheirs 1\n>>>>>>> feature\n" + "mid\n" + second
+
+	path := filepath.Join(t.TempDir(), "file.go")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := conflict.Parse("file.go", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewHunkReviewModel(context.Background(), []conflictedFile{{path: path, display: "file.go", file: parsed, mode: 0o644}}, ai.Config{}, usage.Config{}, "")
+	m.state = StateReviewing
+	m.currentHunk().Resolve(m.currentHunk().Theirs)
+	m.hunkIdx = 1
+
+	cmd := m.quit()
+	if cmd == nil || m.state != StateWriting {
+		t.Fatalf("quit() should write the current file first, state = %v", m.state)
+	}
+	msg, ok := cmd().(fileWrittenMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("write failed: %+v", msg)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "theirs 1\nmid\n" + second; string(got) != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+
+	m.Update(msg)
+	if m.state != StateDone {
+		t.Errorf("state after the write = %v, want StateDone", m.state)
+	}
+}
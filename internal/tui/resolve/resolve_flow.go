@@ -0,0 +1,85 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"huseynovvusal/gitai/internal/config"
+	"huseynovvusal/gitai/internal/conflict"
+	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/ignore"
+)
+
+func RunResolveFlow(ctx context.Context, cfg *config.Config) {
+	root, err := git.GetGitRoot()
+	if err != nil {
+		panic(err)
+	}
+
+	paths, err := git.GetConflictedFiles()
+	if err != nil {
+		panic(err)
+	}
+
+	if len(paths) == 0 {
+		println("No conflicted files to resolve.")
+		return
+	}
+
+	exclude, err := ignore.Load(cfg.Prompt.ExcludePaths)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	var files []conflictedFile
+	for _, p := range paths {
+		if exclude.Match(p) {
+			fmt.Printf("Skipping %s: content is withheld from AI (%s)\n", p, ignore.FileName)
+			continue
+		}
+
+		// git reports conflicted paths relative to the repository root
+		abs := filepath.Join(root, p)
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			// e.g. deleted by us/them; nothing to merge textually
+			fmt.Printf("Skipping %s: %v\n", p, err)
+			continue
+		}
+
+		content, err := os.ReadFile(abs)
+		if err != nil {
+			panic(err)
+		}
+
+		parsed, err := conflict.Parse(p, string(content))
+		if err != nil {
+			fmt.Printf("Skipping %s: %v\n", p, err)
+			continue
+		}
+
+		if len(parsed.Hunks()) == 0 {
+			fmt.Printf("Skipping %s: no conflict markers found\n", p)
+			continue
+		}
+
+		files = append(files, conflictedFile{path: abs, display: p, file: parsed, mode: info.Mode().Perm()})
+	}
+
+	if len(files) == 0 {
+		return
+	}
+
+	model := NewHunkReviewModel(ctx, files, cfg.AIConfig(), cfg.Usage, cfg.TUI.Editor)
+	program := tea.NewProgram(&model, tea.WithContext(ctx))
+
+	if _, err := program.Run(); err != nil {
+		panic(err)
+	}
+}
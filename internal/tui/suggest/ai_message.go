@@ -2,19 +2,35 @@ package suggest
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/cache"
+	"huseynovvusal/gitai/internal/config"
 	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/ignore"
+	"huseynovvusal/gitai/internal/lint"
+	"huseynovvusal/gitai/internal/prompt"
+	"huseynovvusal/gitai/internal/scope"
 	"huseynovvusal/gitai/internal/security"
+	"huseynovvusal/gitai/internal/trailer"
 	"huseynovvusal/gitai/internal/tui/suggest/shared"
+	"huseynovvusal/gitai/internal/usage"
 )
 
 type aiDoneMsg struct {
-	message string
+	message    string
+	provider   ai.Provider
+	redacted   bool
+	cached     bool
+	record     usage.Record
+	violations []lint.Violation
+	ticket     string
+	signOff    string
 }
 
 type aiErrorMsg struct {
@@ -30,9 +46,9 @@ type pushResultMsg struct {
 }
 
 type commitSecurityWarningMsg struct {
-	err    error
-	diff   string
-	status string
+	findings []security.Finding
+	diff     string
+	status   string
 }
 
 type State int
@@ -55,13 +71,23 @@ type AIMessageModel struct {
 	spinner       spinner.Model
 	errMsg        string
 	cancel        bool
-	provider      ai.Provider
+	cfg           *config.Config
 	savedDiff     string
 	savedStatus   string
+	redacted      bool
+	generatedBy   ai.Provider
+	cached        bool
+	record        usage.Record
+	violations    []lint.Violation
+	ticket        string
+	signOff       string
+	coAuthors     []bool
+	exclude       *ignore.Matcher
+	templates     *prompt.Templates
 	ctx           context.Context
 }
 
-func NewAIMessageModel(ctx context.Context, files []string, provider ai.Provider) AIMessageModel {
+func NewAIMessageModel(ctx context.Context, files []string, cfg *config.Config, exclude *ignore.Matcher, templates *prompt.Templates) AIMessageModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = shared.CursorStyle
@@ -74,13 +100,19 @@ func NewAIMessageModel(ctx context.Context, files []string, provider ai.Provider
 		errMsg:        "",
 		cancel:        false,
 		ctx:           ctx,
-		provider:      provider,
+		cfg:           cfg,
+		exclude:       exclude,
+		templates:     templates,
+		coAuthors:     make([]bool, len(cfg.Trailers.CoAuthors)),
 	}
 }
 
-func runAIAsync(ctx context.Context, provider ai.Provider, files []string) tea.Cmd {
+func runAIAsync(ctx context.Context, cfg *config.Config, templates *prompt.Templates, files []string, exclude *ignore.Matcher) tea.Cmd {
 	return func() tea.Msg {
-		diff, err := git.GetChangesForFiles(files)
+		// withheld files are committed but only their names reach the provider
+		included, withheld := exclude.Split(files)
+
+		diff, err := git.GetChangesForFiles(included)
 		if err != nil {
 			return aiErrorMsg{err: err}
 		}
@@ -90,29 +122,139 @@ func runAIAsync(ctx context.Context, provider ai.Provider, files []string) tea.C
 			return aiErrorMsg{err: err}
 		}
 
-		err = security.CheckDiffSafety(diff)
-		if err != nil {
-			return commitSecurityWarningMsg{err: err, diff: diff, status: status}
+		diff += ignore.WithheldSummary(withheld)
+
+		// redaction also covers context and removed lines, which the
+		// findings below do not, so it runs whether or not they are empty
+		if cfg.Security.Policy == security.PolicyRedact {
+			return redactAndGenerate(ctx, cfg, templates, files, diff, status)
 		}
 
-		commitMessage, err := ai.GenerateCommitMessage(ctx, provider, diff, status)
+		findings, err := security.CheckDiffSafety(diff, cfg.Security, cfg.ScanProvider())
 		if err != nil {
 			return aiErrorMsg{err: err}
 		}
 
-		return aiDoneMsg{message: commitMessage}
+		if len(findings) > 0 {
+			switch cfg.Security.Policy {
+			case security.PolicyBlock:
+				return aiErrorMsg{err: fmt.Errorf("diff blocked by security policy:\n%s", security.FormatFindings(findings))}
+			default:
+				return commitSecurityWarningMsg{findings: findings, diff: diff, status: status}
+			}
+		}
+
+		return generate(ctx, cfg, templates, files, diff, status, false)
+	}
+}
+
+// generate renders the prompt and asks the provider chain for a commit
+// message, answering from the response cache when the same prompt was sent
+// before to the same provider and model.
+func generate(ctx context.Context, cfg *config.Config, templates *prompt.Templates, files []string, diff, status string, redacted bool) tea.Msg {
+	data := prompt.Gather(cfg, files, diff, status)
+	p, err := templates.Render(data)
+	if err != nil {
+		return aiErrorMsg{err: err}
+	}
+
+	var signOff string
+	if cfg.Trailers.SignOff {
+		if signOff, err = git.CommitterIdent(); err != nil {
+			return aiErrorMsg{err: err}
+		}
+	}
+
+	aiCfg := cfg.AIConfig()
+	sent := p.ForOutput(aiCfg.Output)
+	key := cache.Key(string(aiCfg.Provider), aiCfg.Model(), sent.System, sent.User)
+
+	var responses *cache.Cache
+	if cfg.Cache.Enabled {
+		// the cache is an optimisation; without a cache dir we just call the provider
+		responses, _ = cache.Open(cfg.Cache)
+	}
+	if responses != nil {
+		if e, ok := responses.Get(key); ok {
+			return aiDoneMsg{message: e.Message, provider: ai.Provider(e.Provider), redacted: redacted, cached: true, violations: lint.Lint(cfg.LintConfig(), e.Message), ticket: data.Ticket, signOff: signOff}
+		}
+	}
+
+	gen, err := ai.GenerateCommitMessage(ctx, aiCfg, p)
+	if err != nil {
+		return aiErrorMsg{err: err}
+	}
+
+	record := usage.Track(cfg.Usage, usage.OpCommit, gen.Call)
+	message, violations := checkMessage(ctx, cfg, aiCfg, p, gen.Message, &record)
+	if cfg.Scope.Enforce && data.Scope != "" {
+		message = scope.Apply(message, data.Scope)
+		violations = lint.Lint(cfg.LintConfig(), message)
+	}
+
+	if responses != nil {
+		_ = responses.Put(key, cache.Entry{Provider: string(gen.Provider), Model: gen.Model, Message: message})
+	}
+	return aiDoneMsg{message: message, provider: gen.Provider, redacted: redacted, record: record, violations: violations, ticket: data.Ticket, signOff: signOff}
+}
+
+// checkMessage lints a generated message and, depending on
+// lint.on_violation, repairs it and asks the provider once more to fix what
+// repair could not. A re-prompt is added to record. It returns the best
+// message and its remaining violations.
+func checkMessage(ctx context.Context, cfg *config.Config, aiCfg ai.Config, p ai.Prompt, message string, record *usage.Record) (string, []lint.Violation) {
+	rules := cfg.LintConfig()
+	violations := lint.Lint(rules, message)
+	if len(violations) == 0 || rules.OnViolation == lint.ActionOff {
+		return message, violations
+	}
+
+	message, violations = lint.Repair(rules, message)
+	if len(violations) == 0 || rules.OnViolation != lint.ActionReprompt {
+		return message, violations
+	}
+
+	problems := make([]string, len(violations))
+	for i, v := range violations {
+		problems[i] = v.Message
+	}
+	fixed, err := ai.RepairCommitMessage(ctx, aiCfg, p, message, problems)
+	if err != nil {
+		// keep the repaired message; its violations are shown
+		return message, violations
+	}
+	record.Add(usage.Track(cfg.Usage, usage.OpCommit, fixed.Call))
+
+	if m, vs := lint.Repair(rules, fixed.Message); len(vs) < len(violations) {
+		return m, vs
+	}
+	return message, violations
+}
+
+// redactAndGenerate replaces detected secrets with placeholders before the
+// diff is sent to the provider; the message is marked redacted only when
+// something was replaced.
+func redactAndGenerate(ctx context.Context, cfg *config.Config, templates *prompt.Templates, files []string, diff, status string) tea.Msg {
+	redacted, err := security.RedactDiff(diff, cfg.Security, cfg.ScanProvider())
+	if err != nil {
+		return aiErrorMsg{err: err}
+	}
+
+	return generate(ctx, cfg, templates, files, redacted, status, redacted != diff)
+}
+
+// runRedactAfterWarningAsync resumes generation with the saved diff redacted.
+func runRedactAfterWarningAsync(ctx context.Context, cfg *config.Config, templates *prompt.Templates, files []string, diff, status string) tea.Cmd {
+	return func() tea.Msg {
+		return redactAndGenerate(ctx, cfg, templates, files, diff, status)
 	}
 }
 
 // runGenerateAfterWarningAsync resumes commit message generation using the
 // previously saved diff/status after the user confirmed the warning.
-func runGenerateAfterWarningAsync(ctx context.Context, provider ai.Provider, diff, status string) tea.Cmd {
+func runGenerateAfterWarningAsync(ctx context.Context, cfg *config.Config, templates *prompt.Templates, files []string, diff, status string) tea.Cmd {
 	return func() tea.Msg {
-		commitMessage, err := ai.GenerateCommitMessage(ctx, provider, diff, status)
-		if err != nil {
-			return aiErrorMsg{err: err}
-		}
-		return aiDoneMsg{message: commitMessage}
+		return generate(ctx, cfg, templates, files, diff, status, false)
 	}
 }
 
@@ -123,9 +265,9 @@ func runCommitAsync(files []string, message string) tea.Cmd {
 	}
 }
 
-func runPushAsync() tea.Cmd {
+func runPushAsync(remote string) tea.Cmd {
 	return func() tea.Msg {
-		err := git.Push()
+		err := git.Push(remote)
 		return pushResultMsg{err: err}
 	}
 }
@@ -133,7 +275,7 @@ func runPushAsync() tea.Cmd {
 func (m *AIMessageModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
-		runAIAsync(m.ctx, m.provider, m.files),
+		runAIAsync(m.ctx, m.cfg, m.templates, m.files, m.exclude),
 	)
 }
 
@@ -150,7 +292,13 @@ func (m *AIMessageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.state == StateSecurityWarning {
 				m.state = StateGenerating
 				m.errMsg = ""
-				return m, runGenerateAfterWarningAsync(m.ctx, m.provider, m.savedDiff, m.savedStatus)
+				return m, runGenerateAfterWarningAsync(m.ctx, m.cfg, m.templates, m.files, m.savedDiff, m.savedStatus)
+			}
+		case "r":
+			if m.state == StateSecurityWarning {
+				m.state = StateGenerating
+				m.errMsg = ""
+				return m, runRedactAfterWarningAsync(m.ctx, m.cfg, m.templates, m.files, m.savedDiff, m.savedStatus)
 			}
 		case "n":
 			if m.state == StateSecurityWarning {
@@ -163,14 +311,18 @@ func (m *AIMessageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = StateCommitting
 				m.errMsg = ""
 
-				return m, tea.Batch(m.spinner.Tick, runCommitAsync(m.files, m.commitMessage))
+				return m, tea.Batch(m.spinner.Tick, runCommitAsync(m.files, m.finalMessage()))
+			}
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if i := int(msg.String()[0] - '1'); m.state == StateGenerated && i < len(m.coAuthors) {
+				m.coAuthors[i] = !m.coAuthors[i]
 			}
 		case "p":
 			// allow pushing only when we've committed
 			if m.state == StateCommitted {
 				m.state = StatePushing
 				m.errMsg = ""
-				return m, tea.Batch(m.spinner.Tick, runPushAsync())
+				return m, tea.Batch(m.spinner.Tick, runPushAsync(m.cfg.Git.Remote))
 			}
 		}
 	case spinner.TickMsg:
@@ -180,6 +332,13 @@ func (m *AIMessageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case aiDoneMsg:
 		m.commitMessage = msg.message
+		m.redacted = msg.redacted
+		m.generatedBy = msg.provider
+		m.cached = msg.cached
+		m.record = msg.record
+		m.violations = msg.violations
+		m.ticket = msg.ticket
+		m.signOff = msg.signOff
 		m.state = StateGenerated
 		return m, nil
 
@@ -210,19 +369,44 @@ func (m *AIMessageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.errMsg = ""
 		return m, tea.Quit
 	case commitSecurityWarningMsg:
-		if msg.err != nil {
-			// save context so we can resume generation if the user confirms
-			m.savedDiff = msg.diff
-			m.savedStatus = msg.status
-			m.state = StateSecurityWarning
-			m.errMsg = msg.err.Error()
-			return m, nil
-		}
+		// save context so we can resume generation if the user confirms
+		m.savedDiff = msg.diff
+		m.savedStatus = msg.status
+		m.state = StateSecurityWarning
+		m.errMsg = security.FormatFindings(msg.findings)
+		return m, nil
 	}
 
 	return m, nil
 }
 
+// finalMessage is the generated message with the branch ticket, the selected
+// co-authors and the sign-off added.
+func (m *AIMessageModel) finalMessage() string {
+	msg := trailer.AddTicket(m.cfg.Ticket, m.commitMessage, m.ticket)
+	for i, author := range m.cfg.Trailers.CoAuthors {
+		if m.coAuthors[i] {
+			msg = trailer.Add(msg, "Co-authored-by", author)
+		}
+	}
+	if m.signOff != "" {
+		msg = trailer.Add(msg, "Signed-off-by", m.signOff)
+	}
+	return msg
+}
+
+// generatedByNote names the provider that wrote the message and flags a fallback.
+func (m *AIMessageModel) generatedByNote() string {
+	note := "Generated by " + string(m.generatedBy)
+	if m.generatedBy != m.cfg.Provider() {
+		note += " (fallback; " + string(m.cfg.Provider()) + " failed)"
+	}
+	if m.cached {
+		note += ", from cache (run with --no-cache to regenerate)"
+	}
+	return note
+}
+
 func (m *AIMessageModel) View() string {
 	if m.cancel {
 		return shared.ErrorStyle.Render("Commit cancelled.") + "\n"
@@ -250,7 +434,7 @@ func (m *AIMessageModel) View() string {
 		var b strings.Builder
 		header := shared.HeaderStyle.Render("Committed successfully:")
 		b.WriteString("\n" + header + "\n")
-		b.WriteString(m.commitMessage + "\n")
+		b.WriteString(m.finalMessage() + "\n")
 		b.WriteString("\n[p] Push   [x] Cancel\n")
 		return b.String()
 
@@ -258,14 +442,40 @@ func (m *AIMessageModel) View() string {
 		var b strings.Builder
 		header := shared.HeaderStyle.Render("Pushed successfully:")
 		b.WriteString("\n" + header + "\n")
-		b.WriteString(m.commitMessage + "\n")
+		b.WriteString(m.finalMessage() + "\n")
 		return b.String()
 
 	case StateGenerated:
 		var b strings.Builder
 		header := shared.HeaderStyle.Render("AI commit message suggestion:")
 		b.WriteString("\n" + header + "\n")
-		b.WriteString(m.commitMessage + "\n")
+		b.WriteString(m.finalMessage() + "\n")
+		b.WriteString("\n" + shared.MutedStyle.Render(m.generatedByNote()) + "\n")
+		if !m.cached {
+			b.WriteString(shared.MutedStyle.Render(m.record.Summary()) + "\n")
+		}
+		if m.redacted {
+			b.WriteString("\n" + shared.CheckedStyle.Render("Sensitive values were redacted before sending the diff.") + "\n")
+		}
+		if len(m.violations) > 0 {
+			b.WriteString("\n" + shared.ErrorStyle.Render("The message breaks these commit rules:") + "\n")
+			for _, v := range m.violations {
+				b.WriteString("  " + v.String() + "\n")
+			}
+		}
+		if len(m.coAuthors) > 0 {
+			b.WriteString("\nCo-authors (press the number to toggle):\n")
+			for i, author := range m.cfg.Trailers.CoAuthors {
+				if i >= 9 {
+					break
+				}
+				checked := shared.CheckedStyle.Render("[ ]")
+				if m.coAuthors[i] {
+					checked = shared.CheckedStyle.Render("[x]")
+				}
+				b.WriteString(fmt.Sprintf("  %s %d %s\n", checked, i+1, author))
+			}
+		}
 		// TODO: Implement edit and regenerate functionality
 		// For now, we just show commit and cancel options
 		// b.WriteString("\n[e] Edit   [r] Regenerate   [c] Commit   [x] Cancel\n")
@@ -277,7 +487,7 @@ func (m *AIMessageModel) View() string {
 		b.WriteString("\n" + header + "\n")
 		b.WriteString(m.errMsg + "\n")
 		b.WriteString("\nDo you wish to continue?\n")
-		b.WriteString("\n[Y] yes   [n] no\n")
+		b.WriteString("\n[Y] yes   [r] redact   [n] no\n")
 		return b.String()
 	default:
 		// fallback - shouldn't happen
@@ -6,20 +6,28 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"huseynovvusal/gitai/internal/ignore"
 	"huseynovvusal/gitai/internal/tui/suggest/shared"
 )
 
 type FileSelectorModel struct {
 	files    []string
+	withheld map[int]bool
 	selected map[int]bool
 	cursor   int
 	quitting bool
 	done     bool
 }
 
-func NewFileSelectorModel(files []string) FileSelectorModel {
+func NewFileSelectorModel(files []string, exclude *ignore.Matcher) FileSelectorModel {
+	withheld := make(map[int]bool)
+	for i, f := range files {
+		withheld[i] = exclude.Match(f)
+	}
+
 	return FileSelectorModel{
 		files:    files,
+		withheld: withheld,
 		selected: make(map[int]bool),
 		cursor:   0,
 		quitting: false,
@@ -82,7 +90,7 @@ func (m *FileSelectorModel) View() string {
 
 		for i, file := range m.files {
 			if m.selected[i] {
-				line := fmt.Sprintf(" - %s", shared.FileStyle.Render(file))
+				line := fmt.Sprintf(" - %s", m.renderFile(i, file))
 				b.WriteString(line + "\n")
 			}
 		}
@@ -104,11 +112,11 @@ func (m *FileSelectorModel) View() string {
 
 		cursor := " "
 
-		line := fmt.Sprintf("%s %s %s", cursor, checked, shared.FileStyle.Render(file))
+		line := fmt.Sprintf("%s %s %s", cursor, checked, m.renderFile(i, file))
 
 		if m.cursor == i {
 			cursor = shared.CursorStyle.Render(">")
-			line = shared.SelectedStyle.Render(fmt.Sprintf("%s %s %s", cursor, checked, shared.FileStyle.Render(file)))
+			line = shared.SelectedStyle.Render(fmt.Sprintf("%s %s %s", cursor, checked, m.renderFile(i, file)))
 		}
 
 		b.WriteString(line + "\n")
@@ -119,6 +127,15 @@ func (m *FileSelectorModel) View() string {
 	return b.String()
 }
 
+// renderFile styles a file name, marking files whose content is withheld
+// from the AI by .gitaiignore or config.
+func (m *FileSelectorModel) renderFile(i int, file string) string {
+	if m.withheld[i] {
+		return shared.FileStyle.Render(file) + " " + shared.WithheldStyle.Render("(content withheld)")
+	}
+	return shared.FileStyle.Render(file)
+}
+
 func (m *FileSelectorModel) anySelected() bool {
 	for _, selected := range m.selected {
 		if selected {
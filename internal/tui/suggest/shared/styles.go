@@ -11,6 +11,7 @@ var (
 	FileFg     = lipgloss.Color("#31748f")
 	SelectedBg = lipgloss.Color("#26233a")
 	ErrorFg    = lipgloss.Color("#eb6f92")
+	MutedFg    = lipgloss.Color("#6e6a86")
 )
 
 var (
@@ -24,4 +25,8 @@ var (
 	// Selected: keep a distinct background to clearly show selection
 	SelectedStyle = lipgloss.NewStyle().Bold(true).Background(SelectedBg).Foreground(BaseFg)
 	ErrorStyle    = lipgloss.NewStyle().Foreground(ErrorFg).Bold(true)
+	// Withheld: dimmed note for files whose content is never sent to the AI
+	WithheldStyle = lipgloss.NewStyle().Foreground(MutedFg).Italic(true)
+	// Muted: secondary information such as which provider answered
+	MutedStyle = lipgloss.NewStyle().Foreground(MutedFg)
 )
@@ -2,13 +2,15 @@ package suggest
 
 import (
 	"context"
-	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/config"
 	"huseynovvusal/gitai/internal/git"
+	"huseynovvusal/gitai/internal/ignore"
+	"huseynovvusal/gitai/internal/prompt"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func RunSuggestFlow(ctx context.Context, provider ai.Provider) {
+func RunSuggestFlow(ctx context.Context, cfg *config.Config) {
 	files, err := git.GetChangedFiles()
 	if err != nil {
 		panic(err)
@@ -19,7 +21,19 @@ func RunSuggestFlow(ctx context.Context, provider ai.Provider) {
 		return
 	}
 
-	fileSelectorModel := NewFileSelectorModel(files)
+	exclude, err := ignore.Load(cfg.Prompt.ExcludePaths)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	templates, err := prompt.Load(cfg)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	fileSelectorModel := NewFileSelectorModel(files, exclude)
 	fileSelectorProgram := tea.NewProgram(&fileSelectorModel)
 	if _, err := fileSelectorProgram.Run(); err != nil {
 		panic(err)
@@ -41,7 +55,7 @@ func RunSuggestFlow(ctx context.Context, provider ai.Provider) {
 		return
 	}
 
-	aiModel := NewAIMessageModel(ctx, selectedFiles, provider)
+	aiModel := NewAIMessageModel(ctx, selectedFiles, cfg, exclude, templates)
 	aiModelProgram := tea.NewProgram(&aiModel, tea.WithContext(ctx))
 
 	_, err = aiModelProgram.Run()
@@ -0,0 +1,58 @@
+package usage
+
+import (
+	"strings"
+
+	"huseynovvusal/gitai/internal/ai"
+)
+
+// Price is a model's list price in USD per million tokens.
+type Price struct {
+	Model  string  `mapstructure:"model"`
+	Input  float64 `mapstructure:"input"`
+	Output float64 `mapstructure:"output"`
+}
+
+func (p Price) cost(prompt, completion int) float64 {
+	return (float64(prompt)*p.Input + float64(completion)*p.Output) / 1e6
+}
+
+// defaultPrices are list prices at the time of writing; override them with
+// usage.prices when they change or for models not listed here.
+var defaultPrices = []Price{
+	{Model: "gpt-3.5-turbo", Input: 0.50, Output: 1.50},
+	{Model: "gpt-4o", Input: 2.50, Output: 10.00},
+	{Model: "gpt-4o-mini", Input: 0.15, Output: 0.60},
+	{Model: "gpt-4.1", Input: 2.00, Output: 8.00},
+	{Model: "gpt-4.1-mini", Input: 0.40, Output: 1.60},
+	{Model: "gpt-4.1-nano", Input: 0.10, Output: 0.40},
+	{Model: "gemini-2.0-flash", Input: 0.10, Output: 0.40},
+	{Model: "gemini-2.0-flash-lite", Input: 0.075, Output: 0.30},
+	{Model: "gemini-2.5-flash", Input: 0.30, Output: 2.50},
+	{Model: "gemini-2.5-pro", Input: 1.25, Output: 10.00},
+}
+
+// lookupPrice finds the price of model. Names match exactly or by the longest
+// prefix, so dated snapshots such as gpt-4o-mini-2024-07-18 use their
+// family's price. Local models are free.
+func lookupPrice(configured []Price, provider ai.Provider, model string) (Price, bool) {
+	if provider == ai.ProviderOllama {
+		return Price{Model: model}, true
+	}
+	if model == "" {
+		return Price{}, false
+	}
+
+	// configured prices come first so they win ties
+	var best Price
+	found := false
+	for _, p := range append(append([]Price{}, configured...), defaultPrices...) {
+		if strings.HasPrefix(model, p.Model) && (!found || len(p.Model) > len(best.Model)) {
+			best, found = p, true
+		}
+	}
+	if found {
+		return best, true
+	}
+	return Price{}, false
+}
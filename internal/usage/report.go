@@ -0,0 +1,143 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Groupings accepted by Summarize.
+const (
+	ByDay      = "day"
+	ByRepo     = "repo"
+	ByProvider = "provider"
+	ByModel    = "model"
+)
+
+// Row is the total of the records sharing one key. Unpriced counts the
+// calls whose model had no known price and are missing from Cost.
+type Row struct {
+	Key              string  `json:"key"`
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost_usd"`
+	Unpriced         int     `json:"unpriced_calls,omitempty"`
+}
+
+func (r *Row) add(rec Record) {
+	r.Calls++
+	r.PromptTokens += rec.PromptTokens
+	r.CompletionTokens += rec.CompletionTokens
+	if rec.Priced {
+		r.Cost += rec.Cost
+	} else {
+		r.Unpriced++
+	}
+}
+
+// Summarize totals records by the given grouping, sorted by key (days
+// ascending), and returns the overall total separately.
+func Summarize(records []Record, by string) ([]Row, Row, error) {
+	var key func(Record) string
+	switch by {
+	case ByDay:
+		key = func(r Record) string { return r.Time.Local().Format(time.DateOnly) }
+	case ByRepo:
+		key = func(r Record) string {
+			if r.Repo == "" {
+				return "(none)"
+			}
+			return r.Repo
+		}
+	case ByProvider:
+		key = func(r Record) string { return r.Provider }
+	case ByModel:
+		key = func(r Record) string {
+			if r.Model == "" {
+				return r.Provider
+			}
+			return r.Provider + "/" + r.Model
+		}
+	default:
+		return nil, Row{}, fmt.Errorf("unknown grouping %q (expected day|repo|provider|model)", by)
+	}
+
+	total := Row{Key: "total"}
+	byKey := map[string]*Row{}
+	for _, rec := range records {
+		k := key(rec)
+		row, ok := byKey[k]
+		if !ok {
+			row = &Row{Key: k}
+			byKey[k] = row
+		}
+		row.add(rec)
+		total.add(rec)
+	}
+
+	rows := make([]Row, 0, len(byKey))
+	for _, row := range byKey {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows, total, nil
+}
+
+// Output formats accepted by WriteReport.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// WriteReport writes rows and their total as an aligned table or as JSON.
+func WriteReport(w io.Writer, format, by string, rows []Row, total Row) error {
+	switch format {
+	case FormatText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "%s\tCALLS\tPROMPT\tCOMPLETION\tCOST\n", headerFor(by))
+		for _, r := range append(rows, total) {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", r.Key, r.Calls, r.PromptTokens, r.CompletionTokens, FormatCost(r.Cost, r.Unpriced))
+		}
+		return tw.Flush()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			By    string `json:"by"`
+			Rows  []Row  `json:"rows"`
+			Total Row    `json:"total"`
+		}{by, rows, total})
+	default:
+		return fmt.Errorf("unknown format %q (expected text|json)", format)
+	}
+}
+
+func headerFor(by string) string {
+	switch by {
+	case ByDay:
+		return "DAY"
+	case ByRepo:
+		return "REPO"
+	case ByModel:
+		return "MODEL"
+	default:
+		return "PROVIDER"
+	}
+}
+
+// FormatCost renders a dollar amount with enough precision for the fractions
+// of a cent a single commit costs, noting calls that could not be priced.
+func FormatCost(cost float64, unpriced int) string {
+	s := fmt.Sprintf("$%.4f", cost)
+	if cost >= 1 {
+		s = fmt.Sprintf("$%.2f", cost)
+	}
+	if unpriced > 0 {
+		s += fmt.Sprintf(" (+%d unpriced)", unpriced)
+	}
+	return s
+}
@@ -0,0 +1,199 @@
+// Package usage keeps a local ledger of provider calls (tokens, latency and
+// estimated cost) and summarises it for `gitai usage`.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"huseynovvusal/gitai/internal/ai"
+	"huseynovvusal/gitai/internal/git"
+)
+
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Prices add to or override the built-in price table.
+	Prices []Price `mapstructure:"prices"`
+}
+
+// Operations recorded in the ledger.
+const (
+	OpCommit  = "commit"
+	OpResolve = "resolve"
+)
+
+// Record is one provider call. Cost is only meaningful when Priced is set,
+// i.e. when the model has a known price.
+type Record struct {
+	Time             time.Time `json:"time"`
+	Repo             string    `json:"repo,omitempty"`
+	Operation        string    `json:"operation"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	Estimated        bool      `json:"estimated,omitempty"`
+	LatencyMS        int64     `json:"latency_ms"`
+	Cost             float64   `json:"cost_usd"`
+	Priced           bool      `json:"priced"`
+}
+
+func (r Record) Tokens() int {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+// Add folds another call for the same operation into r, e.g. a re-prompt.
+func (r *Record) Add(o Record) {
+	r.PromptTokens += o.PromptTokens
+	r.CompletionTokens += o.CompletionTokens
+	r.Estimated = r.Estimated || o.Estimated
+	r.LatencyMS += o.LatencyMS
+	r.Cost += o.Cost
+	r.Priced = r.Priced && o.Priced
+}
+
+// NewRecord prices call with the built-in table and cfg.Prices.
+func NewRecord(cfg Config, operation, repo string, call ai.Call) Record {
+	r := Record{
+		Time:             time.Now(),
+		Repo:             repo,
+		Operation:        operation,
+		Provider:         string(call.Provider),
+		Model:            call.Model,
+		PromptTokens:     call.Usage.PromptTokens,
+		CompletionTokens: call.Usage.CompletionTokens,
+		Estimated:        call.Usage.Estimated,
+		LatencyMS:        call.Latency.Milliseconds(),
+	}
+	if p, ok := lookupPrice(cfg.Prices, call.Provider, call.Model); ok {
+		r.Cost = p.cost(r.PromptTokens, r.CompletionTokens)
+		r.Priced = true
+	}
+	return r
+}
+
+// Ledger is an append-only JSONL file of records.
+type Ledger struct {
+	path string
+}
+
+// Path returns the default ledger location, $XDG_DATA_HOME/gitai/usage.jsonl
+// or ~/.local/share/gitai/usage.jsonl.
+func Path() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, "gitai", "usage.jsonl"), nil
+}
+
+// Open returns the ledger at the default location.
+func Open() (*Ledger, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return New(path), nil
+}
+
+func New(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Append adds r as one line; O_APPEND keeps concurrent writers from
+// interleaving within a line.
+func (l *Ledger) Append(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Records returns every record at or after since; a zero since returns all.
+// Lines that do not parse are skipped so one bad write cannot hide the rest.
+func (l *Ledger) Records(since time.Time) ([]Record, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var r Record
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			continue
+		}
+		if !r.Time.Before(since) {
+			records = append(records, r)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", l.path, err)
+	}
+	return records, nil
+}
+
+// Track prices call and, when cfg.Enabled, appends it to the default ledger
+// with the current repository. The ledger is bookkeeping only: failing to
+// write it never fails the command, so errors are dropped.
+func Track(cfg Config, operation string, call ai.Call) Record {
+	repo, _ := git.GetGitRoot()
+	r := NewRecord(cfg, operation, repo, call)
+	if !cfg.Enabled {
+		return r
+	}
+	if l, err := Open(); err == nil {
+		_ = l.Append(r)
+	}
+	return r
+}
+
+// Summary describes r's tokens and cost in one line, e.g.
+// "~1520 tokens (1480 prompt + 40 completion), est. $0.0008".
+func (r Record) Summary() string {
+	approx := ""
+	if r.Estimated {
+		approx = "~"
+	}
+	s := fmt.Sprintf("%s%d tokens (%d prompt + %d completion)", approx, r.Tokens(), r.PromptTokens, r.CompletionTokens)
+	if r.Priced {
+		return s + ", est. " + FormatCost(r.Cost, 0)
+	}
+	name := r.Model
+	if name == "" {
+		name = r.Provider
+	}
+	return s + ", no price known for " + name
+}
@@ -0,0 +1,111 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"huseynovvusal/gitai/internal/ai"
+)
+
+func TestNewRecord_Pricing(t *testing.T) {
+	tokens := ai.Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000}
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		provider   ai.Provider
+		model      string
+		wantCost   float64
+		wantPriced bool
+	}{
+		{name: "exact", provider: ai.ProviderGPT, model: "gpt-4o", wantCost: 12.50, wantPriced: true},
+		{name: "dated snapshot", provider: ai.ProviderGPT, model: "gpt-4o-mini-2024-07-18", wantCost: 0.75, wantPriced: true},
+		{name: "configured override", cfg: Config{Prices: []Price{{Model: "gpt-4o", Input: 1, Output: 1}}}, provider: ai.ProviderGPT, model: "gpt-4o", wantCost: 2, wantPriced: true},
+		{name: "local model", provider: ai.ProviderOllama, model: "llama3.1:8b", wantCost: 0, wantPriced: true},
+		{name: "unknown model", provider: ai.ProviderGPT, model: "my-finetune", wantPriced: false},
+		{name: "gemini cli", provider: ai.ProvideGeminiCLI, wantPriced: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecord(tt.cfg, OpCommit, "", ai.Call{Provider: tt.provider, Model: tt.model, Usage: tokens})
+			if r.Priced != tt.wantPriced || r.Cost != tt.wantCost {
+				t.Errorf("cost = %v (priced %v), want %v (priced %v)", r.Cost, r.Priced, tt.wantCost, tt.wantPriced)
+			}
+		})
+	}
+}
+
+func TestLedger_AppendAndRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitai", "usage.jsonl")
+	l := New(path)
+
+	old := Record{Time: time.Now().Add(-48 * time.Hour), Provider: "gpt", PromptTokens: 10}
+	recent := Record{Time: time.Now(), Provider: "gemini", PromptTokens: 20}
+	for _, r := range []Record{old, recent} {
+		if err := l.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a torn or foreign line must not hide the others
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{not json\n")
+	f.Close()
+
+	all, err := l.Records(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("got %d records, want 2", len(all))
+	}
+
+	since, err := l.Records(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(since) != 1 || since[0].Provider != "gemini" {
+		t.Errorf("Records(since) = %+v, want only the recent record", since)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	records := []Record{
+		{Time: day, Repo: "/src/a", Provider: "gpt", PromptTokens: 100, CompletionTokens: 10, Cost: 0.01, Priced: true},
+		{Time: day, Repo: "/src/b", Provider: "gpt", PromptTokens: 200, CompletionTokens: 20, Cost: 0.02, Priced: true},
+		{Time: day.AddDate(0, 0, 1), Repo: "/src/a", Provider: "geminicli", PromptTokens: 50, CompletionTokens: 5},
+	}
+
+	rows, total, err := Summarize(records, ByProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Key != "geminicli" || rows[1].Key != "gpt" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[1].Calls != 2 || rows[1].PromptTokens != 300 || rows[0].Unpriced != 1 {
+		t.Errorf("rows = %+v", rows)
+	}
+	if total.Calls != 3 || total.CompletionTokens != 35 || total.Unpriced != 1 {
+		t.Errorf("total = %+v", total)
+	}
+
+	days, _, err := Summarize(records, ByDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 || days[0].Key != "2025-03-01" {
+		t.Errorf("days = %+v", days)
+	}
+
+	if _, _, err := Summarize(records, "week"); err == nil {
+		t.Error("expected an error for an unknown grouping")
+	}
+}